@@ -0,0 +1,195 @@
+package types
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Clone returns a deep copy of v. Pointers, slices, and maps are copied into
+// new memory, so that the copy can be modified (for example, with Sort) while
+// other goroutines read the original.
+//
+// Clone preserves the shape of v: if two pointers, slices, or maps in v refer
+// to the same memory, so do the corresponding values in the copy, and cyclic
+// values are copied into the same cycle. Slices are copied with a capacity
+// equal to their length, and are only considered the same memory if they
+// also have the same length; a pointer into the middle of a copied value is
+// copied separately.
+//
+// Only exported struct fields are copied, following the other functions in
+// this package, and unexported fields are zero in the copy; the
+// CloneUnexported option copies them as well. The well known types that Less
+// documents are copied whole. Channels, functions, and unsafe pointers are
+// not copied: the copy refers to the same channel, function, or memory.
+func Clone[T any](v T) T {
+	return CloneWith(v)
+}
+
+// CloneWith is like Clone, but with options. Only CloneUnexported affects
+// cloning.
+func CloneWith[T any](v T, opts ...Option) T {
+	var dst T
+	c := &cloner{o: newOptions(opts)}
+	c.clone(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(&v).Elem())
+	return dst
+}
+
+// CloneUnexported deep copies unexported struct fields with Clone, rather than
+// leaving them zero. Copying the unexported fields of types from other
+// packages can copy state that those packages do not expect to be copied,
+// such as a sync.Mutex.
+func CloneUnexported() Option {
+	return func(o *options) { o.cloneUnexported = true }
+}
+
+type cloner struct {
+	o *options
+
+	// copies maps memory that has been copied to its copy. Slices
+	// include their length, since subslices share memory.
+	copies map[cloneKey]reflect.Value
+}
+
+type cloneKey struct {
+	ptr unsafe.Pointer
+	t   reflect.Type
+	len int
+}
+
+// copied returns the copy of the memory at k, if it has been copied.
+func (c *cloner) copied(k cloneKey) (reflect.Value, bool) {
+	v, ok := c.copies[k]
+	return v, ok
+}
+
+func (c *cloner) record(k cloneKey, v reflect.Value) {
+	if c.copies == nil {
+		c.copies = make(map[cloneKey]reflect.Value)
+	}
+	c.copies[k] = v
+}
+
+// clone deep copies src into dst, which must be settable and zero.
+func (c *cloner) clone(dst, src reflect.Value) {
+	t := src.Type()
+	if knownComparer(t) != nil {
+		if clone := knownCloners[t]; clone != nil {
+			clone(dst, src)
+		} else {
+			dst.Set(src)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		k := cloneKey{ptr: src.UnsafePointer(), t: t}
+		if cp, ok := c.copied(k); ok {
+			dst.Set(cp)
+			return
+		}
+		cp := reflect.New(t.Elem())
+		c.record(k, cp)
+		c.clone(cp.Elem(), src.Elem())
+		dst.Set(cp)
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		k := cloneKey{ptr: src.UnsafePointer(), t: t, len: src.Len()}
+		if cp, ok := c.copied(k); ok {
+			dst.Set(cp)
+			return
+		}
+		cp := reflect.MakeSlice(t, src.Len(), src.Len())
+		c.record(k, cp)
+		for i := range src.Len() {
+			c.clone(cp.Index(i), src.Index(i))
+		}
+		dst.Set(cp)
+
+	case reflect.Array:
+		for i := range src.Len() {
+			c.clone(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		k := cloneKey{ptr: src.UnsafePointer(), t: t}
+		if cp, ok := c.copied(k); ok {
+			dst.Set(cp)
+			return
+		}
+		cp := reflect.MakeMapWithSize(t, src.Len())
+		c.record(k, cp)
+		for iter := src.MapRange(); iter.Next(); {
+			key, val := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+			c.clone(key, iter.Key())
+			c.clone(val, iter.Value())
+			cp.SetMapIndex(key, val)
+		}
+		dst.Set(cp)
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := src.Elem()
+		cp := reflect.New(elem.Type()).Elem()
+		c.clone(cp, elem)
+		dst.Set(cp)
+
+	case reflect.Struct:
+		if c.o.cloneUnexported && !src.CanAddr() {
+			// Unexported fields can only be read through their
+			// address, so we read from an addressable copy.
+			tmp := reflect.New(t).Elem()
+			tmp.Set(src)
+			src = tmp
+		}
+		for i := range t.NumField() {
+			switch {
+			case t.Field(i).IsExported():
+				c.clone(dst.Field(i), src.Field(i))
+			case c.o.cloneUnexported:
+				c.clone(exposed(dst.Field(i)), exposed(src.Field(i)))
+			}
+		}
+
+	default:
+		dst.Set(src)
+	}
+}
+
+// exposed returns the addressable value v, which may have been obtained
+// through an unexported field, as if it were not.
+func exposed(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// Sorted returns a deep copy of v that is sorted with the rules of Sort,
+// leaving v untouched. Because the copy is new memory, this is safe to call on
+// values that other goroutines are reading, and unlike Sort, it sorts an array
+// passed by value.
+//
+// Unlike Clone, the copy includes unexported fields, as if CloneUnexported
+// were used, so that it differs from v only in order. As CloneUnexported
+// warns, this copies state that some types do not expect to be copied: a
+// value holding a sync.Mutex should not be passed while the mutex is locked.
+func Sorted[T any](v T) T {
+	return SortedWith(v)
+}
+
+// SortedWith is like Sorted, but with options that modify how the copy is
+// made and sorted.
+func SortedWith[T any](v T, opts ...Option) T {
+	c := CloneWith(v, append([]Option{CloneUnexported()}, opts...)...)
+	innerSort(newState(opts), reflect.ValueOf(&c).Elem())
+	return c
+}
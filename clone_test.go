@@ -0,0 +1,140 @@
+package types
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	type node struct {
+		Val  int
+		Next *node
+	}
+	type graph struct {
+		Head   *node
+		Shared *node
+		Nodes  []*node
+		ByName map[string]*node
+		Ints   []int
+		Alias  []int
+		Any    any
+		When   time.Time
+		Big    *big.Int
+		hidden int
+	}
+
+	// A cycle, a shared node, and an aliased slice.
+	a := &node{Val: 1}
+	b := &node{Val: 2, Next: a}
+	a.Next = b
+	ints := []int{3, 1, 2}
+	g := &graph{
+		Head:   a,
+		Shared: b,
+		Nodes:  []*node{a, b},
+		ByName: map[string]*node{"a": a},
+		Ints:   ints,
+		Alias:  ints,
+		Any:    []int{5, 4},
+		When:   time.Unix(1, 2),
+		Big:    big.NewInt(7),
+		hidden: 1,
+	}
+
+	c := Clone(g)
+	if !Equal(g, c) {
+		t.Fatalf("got clone unequal to original")
+	}
+	if c.Head == a || c.Head.Next == b || c.Nodes[0] == a {
+		t.Errorf("got clone sharing pointers with the original")
+	}
+	if c.Head.Next.Next != c.Head || c.Shared != c.Head.Next || c.Nodes[1] != c.Shared || c.ByName["a"] != c.Head {
+		t.Errorf("got clone with a different shape than the original")
+	}
+	if &c.Ints[0] != &c.Alias[0] || &c.Ints[0] == &ints[0] {
+		t.Errorf("got clone with a different slice aliasing than the original")
+	}
+	if c.hidden != 0 {
+		t.Errorf("got unexported field %d, exp skipped", c.hidden)
+	}
+
+	// Modifying the clone does not modify the original.
+	Sort(c)
+	c.Big.SetInt64(8)
+	c.Head.Val = 10
+	if !reflect.DeepEqual(ints, []int{3, 1, 2}) || !reflect.DeepEqual(g.Any, []int{5, 4}) {
+		t.Errorf("got original modified by sorting the clone: %v, %v", ints, g.Any)
+	}
+	if g.Big.Int64() != 7 || a.Val != 1 {
+		t.Errorf("got original modified through the clone")
+	}
+
+	if cu := CloneWith(g, CloneUnexported()); cu.hidden != 1 {
+		t.Errorf("got unexported field %d, exp copied", cu.hidden)
+	}
+}
+
+func TestCloneUnexported(t *testing.T) {
+	type inner struct {
+		p *int
+	}
+	type outer struct {
+		m map[string]inner
+		s []any
+	}
+
+	n := 1
+	o := outer{
+		m: map[string]inner{"a": {&n}},
+		s: []any{inner{&n}},
+	}
+	c := CloneWith(o, CloneUnexported())
+	p, q := c.m["a"].p, c.s[0].(inner).p
+	if p == &n || *p != 1 || p != q {
+		t.Errorf("got unexported pointers %p, %p, exp one shared copy of %d", p, q, n)
+	}
+	if c := Clone(o); c.m != nil || c.s != nil {
+		t.Errorf("got unexported fields copied without CloneUnexported")
+	}
+}
+
+func TestSorted(t *testing.T) {
+	type doc struct {
+		Tags  []string
+		Grid  [3]int
+		ByKey map[string][]int
+		notes []string
+	}
+	orig := doc{
+		Tags:  []string{"b", "c", "a"},
+		Grid:  [3]int{3, 1, 2},
+		ByKey: map[string][]int{"x": {2, 1}},
+		notes: []string{"y", "x"},
+	}
+	before := CloneWith(orig, CloneUnexported())
+
+	// Unexported fields are copied but, as with Sort, not sorted.
+	sorted := Sorted(orig)
+	exp := doc{
+		Tags:  []string{"a", "b", "c"},
+		Grid:  [3]int{1, 2, 3},
+		ByKey: map[string][]int{"x": {1, 2}},
+		notes: []string{"y", "x"},
+	}
+	if !reflect.DeepEqual(sorted, exp) {
+		t.Errorf("got %v != exp %v", sorted, exp)
+	}
+	sorted.notes[0] = "z"
+	if !reflect.DeepEqual(orig, before) {
+		t.Errorf("got original modified: %v", orig)
+	}
+
+	if arr := Sorted([3]int{3, 1, 2}); arr != [3]int{1, 2, 3} {
+		t.Errorf("got array %v, exp sorted", arr)
+	}
+	if s := SortedWith([]int{1, 3, 2}, Descending()); !reflect.DeepEqual(s, []int{3, 2, 1}) {
+		t.Errorf("got %v, exp sorted descending", s)
+	}
+}
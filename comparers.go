@@ -0,0 +1,107 @@
+package types
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+)
+
+// registeredComparers contains comparers registered with RegisterComparer.
+var registeredComparers sync.Map // reflect.Type => comparer
+
+// RegisterComparer registers cmp as the comparison for all values of type T,
+// which must return a negative number if a < b, 0 if a == b, and a positive
+// number if a > b. This gives an order to types that cannot have comparison
+// methods added, such as types from other packages.
+//
+// A registered comparer takes precedence over everything else that this
+// package uses to compare a type: comparison methods, the well known types
+// that Less documents, and comparing by kind. Like types with comparison
+// methods, registered types are compared as a whole: Sort does not sort
+// within them, Diff reports them as a whole, and Hash only hashes their
+// presence.
+//
+// Registering a comparer for a type replaces any comparer previously
+// registered for it. Registration is global and should be done before
+// comparing, such as in an init function; functions previously returned from
+// CompareFunc and EqualFunc do not see new registrations. To use comparers
+// without global state, see WithComparers.
+func RegisterComparer[T any](cmp func(a, b T) int) {
+	t, c := reflect.TypeFor[T](), comparerOf(cmp)
+
+	// Every compiled type that contains T compiled T without the
+	// comparer, so we drop everything compiled.
+	compileMu.Lock()
+	defer compileMu.Unlock()
+	registeredComparers.Store(t, c)
+	compiledTypes.Clear()
+}
+
+// registeredComparer returns the comparer registered for t, or nil.
+func registeredComparer(t reflect.Type) comparer {
+	if c, ok := registeredComparers.Load(t); ok {
+		return c.(comparer)
+	}
+	return nil
+}
+
+// Comparers is a set of comparers that is used only by the comparisons it
+// is passed to with WithComparers, rather than globally as with
+// RegisterComparer. The zero value is an empty set ready to use.
+type Comparers struct {
+	m map[reflect.Type]comparer
+}
+
+// AddComparer adds cmp to cs as the comparison for all values of type T,
+// following the same rules as RegisterComparer. Adding a comparer for a type
+// replaces any comparer previously added for it.
+func AddComparer[T any](cs *Comparers, cmp func(a, b T) int) {
+	if cs.m == nil {
+		cs.m = make(map[reflect.Type]comparer)
+	}
+	cs.m[reflect.TypeFor[T]()] = comparerOf(cmp)
+}
+
+// WithComparers uses the comparers in cs. These take precedence over
+// comparers registered with RegisterComparer for the same types. The
+// comparers are copied when this option is created, so later additions to cs
+// do not affect it.
+func WithComparers(cs *Comparers) Option {
+	m := maps.Clone(cs.m)
+	return func(o *options) {
+		if o.comparers == nil {
+			o.comparers = m // never modified; merging below copies
+			return
+		}
+		merged := maps.Clone(o.comparers)
+		maps.Copy(merged, m)
+		o.comparers = merged
+	}
+}
+
+func comparerOf[T any](cmp func(a, b T) int) comparer {
+	return func(l, r reflect.Value) int {
+		// If T is an interface, a nil value is passed as a nil T.
+		a, _ := l.Interface().(T)
+		b, _ := r.Interface().(T)
+		return cmp(a, b)
+	}
+}
+
+// custom returns the comparer for t from WithComparers or RegisterComparer,
+// or nil.
+func (o *options) custom(t reflect.Type) comparer {
+	if c := o.comparers[t]; c != nil {
+		return c
+	}
+	return registeredComparer(t)
+}
+
+// comparer returns the comparer for t from WithComparers, RegisterComparer, or
+// the well known types, or nil.
+func (o *options) comparer(t reflect.Type) comparer {
+	if c := o.custom(t); c != nil {
+		return c
+	}
+	return knownComparer(t)
+}
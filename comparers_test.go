@@ -0,0 +1,143 @@
+package types
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// money is registered to compare only by Cents.
+type money struct {
+	Cents int
+	Note  string
+}
+
+func init() {
+	RegisterComparer(func(a, b money) int { return a.Cents - b.Cents })
+}
+
+func TestRegisterComparer(t *testing.T) {
+	type account struct {
+		Name    string
+		Balance money
+		History []money
+	}
+
+	l := account{"a", money{1, "x"}, []money{{3, "x"}, {2, "y"}}}
+	r := account{"a", money{1, "y"}, []money{{3, "z"}, {2, "z"}}}
+	if !Equal(l, r) {
+		t.Errorf("got unequal, exp equal by registered comparer")
+	}
+	if d := Diff(l, r); d != nil {
+		t.Errorf("got unexpected diff %v", d)
+	}
+	if Hash(l) != Hash(r) {
+		t.Errorf("got different hashes for equal values")
+	}
+	if !Less(money{1, "z"}, money{2, "a"}) {
+		t.Errorf("got not less, exp less by registered comparer")
+	}
+
+	r.History[1].Cents = 4
+	if d := Diff(l, r); !reflect.DeepEqual(d, []Difference{
+		{".History[1]", money{2, "y"}, money{4, "z"}},
+	}) {
+		t.Errorf("got unexpected diff %v", d)
+	}
+
+	// Sorting uses the comparer.
+	s := []money{{3, "a"}, {1, "b"}, {2, "c"}}
+	Sort(s)
+	if exp := []money{{1, "b"}, {2, "c"}, {3, "a"}}; !reflect.DeepEqual(s, exp) {
+		t.Errorf("got %v != exp %v", s, exp)
+	}
+}
+
+func TestRegisterComparerInvalidates(t *testing.T) {
+	type late struct {
+		A, B int
+	}
+	type wrapper struct {
+		L late
+	}
+
+	l, r := wrapper{late{1, 2}}, wrapper{late{1, 3}}
+	// Registration is global, so this is only unequal on the first run
+	// with -count.
+	if registeredComparer(reflect.TypeFor[late]()) == nil && Equal(l, r) {
+		t.Errorf("got equal before registering, exp unequal")
+	}
+	RegisterComparer(func(a, b late) int { return a.A - b.A })
+	if !Equal(l, r) {
+		t.Errorf("got unequal after registering, exp equal")
+	}
+}
+
+func TestWithComparers(t *testing.T) {
+	type item struct {
+		Name  string
+		Price money
+	}
+	byNote := func(a, b money) int { return strings.Compare(a.Note, b.Note) }
+
+	var cs Comparers
+	AddComparer(&cs, byNote)
+	AddComparer(&cs, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+	opt := WithComparers(&cs)
+
+	l, r := item{"A", money{1, "x"}}, item{"a", money{2, "x"}}
+	if Equal(l, r) {
+		t.Errorf("got equal without scoped comparers, exp unequal")
+	}
+	if !EqualWith(l, r, opt) {
+		t.Errorf("got unequal with scoped comparers, exp equal")
+	}
+	if HashWith(l, opt) != HashWith(r, opt) {
+		t.Errorf("got different hashes with scoped comparers")
+	}
+	if d := DiffWith(l, r, opt); d != nil {
+		t.Errorf("got unexpected diff %v", d)
+	}
+
+	s := []string{"b", "C", "a"}
+	SortWith(s, opt)
+	if exp := []string{"a", "b", "C"}; !reflect.DeepEqual(s, exp) {
+		t.Errorf("got %v != exp %v", s, exp)
+	}
+
+	// Comparers added after creating the option are not used.
+	AddComparer(&cs, func(a, b int) int { return 0 })
+	if EqualWith(1, 2, opt) {
+		t.Errorf("got equal from comparer added after the option")
+	}
+	if !EqualWith(1, 2, WithComparers(&cs)) {
+		t.Errorf("got unequal with new option, exp equal")
+	}
+
+	cmp := CompareFunc[item](opt)
+	if c := cmp(item{"B", money{1, "a"}}, item{"a", money{1, "b"}}); c != 1 {
+		t.Errorf("got compare %d != exp 1", c)
+	}
+
+	// Comparers for interface types are passed nil values.
+	var rs Comparers
+	AddComparer(&rs, func(a, b io.Reader) int {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		case b == nil:
+			return 1
+		}
+		return 0
+	})
+	ropt := WithComparers(&rs)
+	if !LessWith([]io.Reader{nil}, []io.Reader{strings.NewReader("x")}, ropt) {
+		t.Errorf("got nil reader not less than non-nil reader")
+	}
+	if !EqualWith([]io.Reader{nil}, []io.Reader{nil}, ropt) {
+		t.Errorf("got nil readers unequal")
+	}
+}
@@ -0,0 +1,449 @@
+package types
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// CompareFunc returns a function that compares two values of type T following
+// the rules of Compare and the given options. The type is analyzed once and
+// cached, so the returned function does no type analysis when called, and it
+// can be passed directly to slices.SortFunc. Unlike Sort, the returned
+// function does not sort within the values it compares.
+func CompareFunc[T any](opts ...Option) func(a, b T) int {
+	c, o := compile(reflect.TypeFor[T]()), newOptions(opts)
+	var free atomic.Pointer[call[T]]
+	return func(a, b T) int {
+		p := getCall(&free, o, a, b)
+		r := compareResult(c.lteqRoot(&p.s, reflect.ValueOf(&p.a).Elem(), reflect.ValueOf(&p.b).Elem()))
+		p.put(&free)
+		return r
+	}
+}
+
+// EqualFunc returns a function that returns whether two values of type T are
+// equal following the rules of Equal and the given options. Like CompareFunc,
+// the type is analyzed once, and the returned function can be passed directly
+// to slices.EqualFunc or slices.CompactFunc.
+func EqualFunc[T any](opts ...Option) func(a, b T) bool {
+	c, o := compile(reflect.TypeFor[T]()), newOptions(opts)
+	var free atomic.Pointer[call[T]]
+	return func(a, b T) bool {
+		p := getCall(&free, o, a, b)
+		_, eq := c.lteq(&p.s, reflect.ValueOf(&p.a).Elem(), reflect.ValueOf(&p.b).Elem())
+		p.put(&free)
+		return eq
+	}
+}
+
+// call holds everything a compiled comparison needs from a typed call. The
+// values must be addressable and the state escapes, so rather than allocating
+// a call for every comparison, each returned function keeps one free call to
+// reuse. Only concurrent or reentrant comparisons allocate.
+type call[T any] struct {
+	s    state
+	a, b T
+}
+
+func getCall[T any](free *atomic.Pointer[call[T]], o *options, a, b T) *call[T] {
+	p := free.Swap(nil)
+	if p == nil {
+		p = new(call[T])
+	}
+	p.s.o, p.a, p.b = o, a, b
+	return p
+}
+
+// put frees p for reuse, dropping any references it holds.
+func (p *call[T]) put(free *atomic.Pointer[call[T]]) {
+	*p = call[T]{}
+	free.Store(p)
+}
+
+// lteqFunc compares two values of the same type.
+type lteqFunc func(s *state, lv, rv reflect.Value) (lt, eq bool)
+
+// compiled is the comparison for a single type. All analysis of the type
+// (its kind, fields, tags, methods, and whether it is a well known type) is
+// done once when compiling, so that comparing is only the work of comparing.
+type compiled struct {
+	t  reflect.Type
+	fn lteqFunc
+
+	// sortable is whether Sort could change anything within a value of
+	// this type; if false, Sort does not walk into values of this type.
+	sortable bool
+}
+
+func (c *compiled) lteq(s *state, lv, rv reflect.Value) (lt, eq bool) {
+	if s.o.ignored(c.t) {
+		return false, true
+	}
+	if s.o.comparers != nil {
+		if cmp := s.o.comparers[c.t]; cmp != nil {
+			return lteqComparer(s, cmp, lv, rv)
+		}
+	}
+	return c.fn(s, lv, rv)
+}
+
+// lteqRoot compares two values at the root of a comparison, which Descending
+// without paths reverses.
+func (c *compiled) lteqRoot(s *state, lv, rv reflect.Value) (lt, eq bool) {
+	if s.o.descending {
+		return s.reversed(c, lv, rv)
+	}
+	return c.lteq(s, lv, rv)
+}
+
+func (c *compiled) compare(s *state, lv, rv reflect.Value) int {
+	return compareResult(c.lteq(s, lv, rv))
+}
+
+func compareResult(lt, eq bool) int {
+	if lt {
+		return -1
+	}
+	if eq {
+		return 0
+	}
+	return 1
+}
+
+// compareFunc returns a function comparing two values with c, for sorting.
+func (c *compiled) compareFunc(s *state) func(l, r reflect.Value) int {
+	return func(l, r reflect.Value) int { return c.compare(s, l, r) }
+}
+
+var (
+	compileMu     sync.Mutex
+	compiledTypes sync.Map // reflect.Type => *compiled
+)
+
+// compile returns the comparison for t, compiling and caching it if this is
+// the first time t has been seen.
+func compile(t reflect.Type) *compiled {
+	if c, ok := compiledTypes.Load(t); ok {
+		return c.(*compiled)
+	}
+
+	compileMu.Lock()
+	defer compileMu.Unlock()
+
+	// Recursive types refer to themselves while compiling. We only
+	// publish types once everything they refer to is compiled.
+	b := &builder{building: make(map[reflect.Type]*compiled)}
+	c := b.compile(t)
+	for t, c := range b.building {
+		compiledTypes.Store(t, c)
+	}
+	return c
+}
+
+type builder struct {
+	building map[reflect.Type]*compiled
+}
+
+func (b *builder) compile(t reflect.Type) *compiled {
+	if c, ok := compiledTypes.Load(t); ok {
+		return c.(*compiled)
+	}
+	if c, ok := b.building[t]; ok {
+		return c
+	}
+	c := &compiled{t: t}
+	b.building[t] = c
+	c.fn = b.build(c)
+	return c
+}
+
+func (b *builder) build(c *compiled) lteqFunc {
+	t := c.t
+	cmp := registeredComparer(t)
+	if cmp == nil {
+		cmp = knownComparer(t)
+	}
+	if cmp != nil {
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			return lteqComparer(s, cmp, lv, rv)
+		}
+	}
+
+	value := b.buildValue(c)
+	m := methodsOf(t)
+	switch {
+	case m.orders():
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			if !callable(lv, rv) {
+				return value(s, lv, rv)
+			}
+			lt, eq = m.lteq(lv, rv)
+			if !eq && s.explaining() {
+				s.explainValues(lt, m.name()+" method: ", lv, rv)
+			}
+			return lt, eq
+		}
+	case m != nil:
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			if !callable(lv, rv) {
+				return value(s, lv, rv)
+			}
+			if callMethod(m.equal, lv, rv).Bool() {
+				return false, true
+			}
+			s.quiet++
+			lt, _ = value(s, lv, rv)
+			s.quiet--
+			if s.explaining() {
+				s.explain("Equal method: %s != %s", formatValue(lv), formatValue(rv))
+			}
+			return lt, false
+		}
+	}
+	return value
+}
+
+// buildValue returns the comparison of t by its kind, ignoring any methods.
+func (b *builder) buildValue(c *compiled) lteqFunc {
+	t := c.t
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			l, r := lv.Bool(), rv.Bool()
+			return explained(s, !l && r, l == r, lv, rv)
+		}
+	case reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64:
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			lt, eq = orderedLtEq(lv.Int(), rv.Int())
+			return explained(s, lt, eq, lv, rv)
+		}
+	case reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			lt, eq = orderedLtEq(lv.Uint(), rv.Uint())
+			return explained(s, lt, eq, lv, rv)
+		}
+	case reflect.Float32,
+		reflect.Float64:
+		bits := t.Bits()
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			lt, eq = floatLtEq(s, lv.Float(), rv.Float(), bits)
+			return explained(s, lt, eq, lv, rv)
+		}
+	case reflect.Complex64,
+		reflect.Complex128:
+		bits := t.Bits()
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			lt, eq = c128lt(s, lv.Complex(), rv.Complex(), bits)
+			return explained(s, lt, eq, lv, rv)
+		}
+	case reflect.Chan:
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			ll, lr := lv.Len(), rv.Len()
+			lt, eq = ll < lr, ll == lr
+			if !eq && s.explaining() {
+				s.explain("chan length %d %s %d", ll, op(lt), lr)
+			}
+			return lt, eq
+		}
+	case reflect.Func,
+		reflect.UnsafePointer:
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			eq = lv.Pointer() == rv.Pointer()
+			if !eq && s.explaining() {
+				s.explain("%s pointers differ", t.Kind())
+			}
+			return false, eq
+		}
+	case reflect.String:
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			lt, eq = orderedLtEq(lv.String(), rv.String())
+			return explained(s, lt, eq, lv, rv)
+		}
+
+	case reflect.Interface:
+		c.sortable = true
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			if lv.IsNil() || rv.IsNil() {
+				return lteqNil(s, "interface", lv.IsNil(), rv.IsNil())
+			}
+			lv, rv = lv.Elem(), rv.Elem()
+			if lt, eq := lteqTypes(lv.Type(), rv.Type()); !eq {
+				if s.explaining() {
+					s.explain("type %s %s %s", lv.Type(), op(lt), rv.Type())
+				}
+				return lt, false
+			}
+			return compile(lv.Type()).lteq(s, lv, rv)
+		}
+
+	case reflect.Struct:
+		fields := fieldsOf(t)
+		elems := make([]*compiled, len(fields))
+		for i, f := range fields {
+			if f.set {
+				elems[i] = b.compile(f.typ.Elem())
+				c.sortable = true
+			} else {
+				elems[i] = b.compile(f.typ)
+				c.sortable = c.sortable || elems[i].sortable
+			}
+		}
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			for i, f := range fields {
+				lf, rf := lv.Field(f.index), rv.Field(f.index)
+				desc := f.desc
+				if s.o.paths {
+					s.push(f.seg)
+					if s.o.ignoredField(s.path, f.sf) {
+						s.pop()
+						continue
+					}
+					desc = desc != (s.o.descPaths != nil && s.o.descAt(s.path))
+				}
+				explaining := desc && s.explaining()
+				if desc {
+					s.desc = !s.desc
+				}
+				if f.set {
+					lt, eq = lteqSet(s, f.typ, elems[i], lf, rf)
+				} else {
+					lt, eq = elems[i].lteq(s, lf, rf)
+				}
+				if desc {
+					s.desc = !s.desc
+				}
+				if s.o.paths {
+					s.pop()
+				}
+				if !eq {
+					if desc {
+						if explaining && s.ex.Rule != "" {
+							s.ex.Rule += " (desc)"
+						}
+						return !lt, false
+					}
+					return lt, false
+				}
+			}
+			return false, true
+		}
+
+	case reflect.Array,
+		reflect.Slice:
+		c.sortable = true
+		elem := b.compile(t.Elem())
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			if s.o.unordered(t) {
+				return lteqSet(s, t, elem, lv, rv)
+			}
+			ll, lr := lv.Len(), rv.Len()
+			if ll == 0 && lr == 0 {
+				return lteqEmpty(s, "slice", lv, rv)
+			}
+			if ll != lr && !s.o.lexicographic {
+				return lteqLen(s, "slice", ll, lr)
+			}
+			for i := range min(ll, lr) {
+				if s.o.paths {
+					lt, eq = s.lteqAt(indexSeg(i), elem, lv.Index(i), rv.Index(i))
+				} else {
+					lt, eq = elem.lteq(s, lv.Index(i), rv.Index(i))
+				}
+				if !eq {
+					return lt, false
+				}
+			}
+			return lteqLen(s, "slice", ll, lr)
+		}
+
+	case reflect.Map:
+		c.sortable = true
+		key, val := b.compile(t.Key()), b.compile(t.Elem())
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			ll, lr := lv.Len(), rv.Len()
+			if ll == 0 && lr == 0 {
+				return lteqEmpty(s, "map", lv, rv)
+			}
+			if ll != lr && !s.o.lexicographic {
+				return lteqLen(s, "map", ll, lr)
+			}
+			lents, rents := sortedEntries(s, key, lv), sortedEntries(s, key, rv)
+			for i := range min(ll, lr) {
+				s.quiet++
+				lt, eq = key.lteq(s, lents[i].k, rents[i].k)
+				s.quiet--
+				if !eq {
+					if s.explaining() {
+						s.explainValues(lt, "map key ", lents[i].k, rents[i].k)
+					}
+					return lt, false
+				}
+			}
+			if ll != lr {
+				return lteqLen(s, "map", ll, lr)
+			}
+			for i, le := range lents {
+				if s.o.paths {
+					lt, eq = s.lteqAt(keySeg(le.k), val, le.v, rents[i].v)
+				} else {
+					lt, eq = val.lteq(s, le.v, rents[i].v)
+				}
+				if !eq {
+					return lt, false
+				}
+			}
+			return false, true
+		}
+
+	case reflect.Pointer:
+		c.sortable = true
+		elem := b.compile(t.Elem())
+		return func(s *state, lv, rv reflect.Value) (lt, eq bool) {
+			if lv.IsNil() || rv.IsNil() {
+				return lteqNil(s, "pointer", lv.IsNil(), rv.IsNil())
+			}
+
+			lptr, rptr := unsafe.Pointer(lv.Pointer()), unsafe.Pointer(rv.Pointer())
+			if lptr == rptr {
+				return false, true
+			}
+			lhas, rhas := s.p.hasOrAdd(lptr), s.p.hasOrAdd(rptr)
+			if !lhas {
+				defer s.p.remove(lptr)
+			}
+			if !rhas {
+				defer s.p.remove(rptr)
+			}
+
+			if lhas || rhas {
+				lt, eq = lhas && !rhas, lhas && rhas
+				if !eq && s.explaining() {
+					if lt {
+						s.explain("recursive pointer < non-recursive")
+					} else {
+						s.explain("non-recursive pointer > recursive")
+					}
+				}
+				return lt, eq
+			}
+
+			return elem.lteq(s, lv.Elem(), rv.Elem())
+		}
+
+	default:
+		return func(*state, reflect.Value, reflect.Value) (lt, eq bool) {
+			return false, false // reflect.Invalid
+		}
+	}
+}
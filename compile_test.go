@@ -0,0 +1,162 @@
+package types
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testCompareFunc[T any](t *testing.T, l, r T) {
+	t.Helper()
+	if got, exp := CompareFunc[T]()(l, r), Compare(l, r); got != exp {
+		t.Errorf("l %v r %v, got compare %d != exp %d", l, r, got, exp)
+	}
+	if got, exp := EqualFunc[T]()(l, r), Equal(l, r); got != exp {
+		t.Errorf("l %v r %v, got equal %v != exp %v", l, r, got, exp)
+	}
+}
+
+func TestCompareFunc(t *testing.T) {
+	type inner struct {
+		A []int
+		B map[string]float64
+		C *inner
+		d int
+	}
+	testCompareFunc(t, 1, 2)
+	testCompareFunc(t, "b", "a")
+	testCompareFunc(t, math.NaN(), math.NaN())
+	testCompareFunc(t, []int{1, 2}, []int{1, 3})
+	testCompareFunc(t, inner{A: []int{1}}, inner{A: []int{1}, d: 1})
+	testCompareFunc(t, inner{B: map[string]float64{"a": 1}}, inner{B: map[string]float64{"a": 0}})
+	testCompareFunc(t, inner{C: &inner{}}, inner{})
+	testCompareFunc(t, newRecursive(2), newRecursive(3))
+	testCompareFunc(t, newRecursive2(2), newRecursive2(2))
+	testCompareFunc(t, tagged{Age: 2}, tagged{Age: 1})
+	testCompareFunc(t, tcompare{1}, tcompare{0})
+	testCompareFunc(t, time.Unix(1, 0), time.Unix(2, 0))
+	testCompareFunc[any](t, 1, 2)
+	if c := CompareFunc[any]()(1, "a"); c != -1 {
+		t.Errorf("got compare %d != exp -1", c)
+	}
+	if !EqualFunc[any]()(nil, nil) {
+		t.Errorf("got nil interfaces unequal, exp equal")
+	}
+	if c := CompareFunc[Lesser]()(nil, &tless2{1}); c != -1 {
+		t.Errorf("got compare %d != exp -1", c)
+	}
+
+	s := []inner{{A: []int{3}}, {A: []int{1}}, {A: []int{2}}}
+	slices.SortFunc(s, CompareFunc[inner]())
+	if !slices.EqualFunc(s, []inner{{A: []int{1}}, {A: []int{2}}, {A: []int{3}}}, EqualFunc[inner]()) {
+		t.Errorf("got %v, exp sorted", s)
+	}
+
+	if c := CompareFunc[item](IgnoreTypes(ignoredID("")))(item{ID: "b"}, item{ID: "a"}); c != 0 {
+		t.Errorf("got compare %d != exp 0 with ignored types", c)
+	}
+}
+
+func TestCompareFuncConcurrent(t *testing.T) {
+	cmp := CompareFunc[[]int]()
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for range 100 {
+				if c := cmp([]int{i}, []int{i + 1}); c != -1 {
+					t.Errorf("got compare %d != exp -1", c)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+type item struct {
+	ID ignoredID
+}
+
+func TestCompileConcurrent(t *testing.T) {
+	type a struct {
+		B []*a
+		C map[int]a
+	}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			if !Equal(a{B: []*a{{}}}, a{B: []*a{{}}}) {
+				t.Errorf("got unequal, exp equal")
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func BenchmarkCompareFuncStruct(b *testing.B) {
+	type l struct{ V int }
+	var ls []l
+	for i := range 1000 {
+		ls = append(ls, l{i})
+	}
+	cmp := CompareFunc[l]()
+	b.ReportAllocs()
+	for b.Loop() {
+		slices.SortFunc(ls, cmp)
+	}
+}
+
+func BenchmarkEqualFuncStruct(b *testing.B) {
+	type l struct {
+		A int
+		B string
+	}
+	x, y := l{1, "a"}, l{1, "a"}
+	eq := EqualFunc[l]()
+	b.ReportAllocs()
+	for b.Loop() {
+		eq(x, y)
+	}
+}
+
+func TestGeneric(t *testing.T) {
+	if !LessT(1, 2) || LessT(2, 1) {
+		t.Errorf("LessT ints wrong")
+	}
+	if !EqualT([]int{1}, []int{1}) || EqualT([]int{1}, []int{2}) {
+		t.Errorf("EqualT slices wrong")
+	}
+	if c := CompareT(tagged{Age: 2}, tagged{Age: 1}); c != -1 {
+		t.Errorf("got compare %d != exp -1", c)
+	}
+	if c := CompareT[any](1, "a"); c != -1 {
+		t.Errorf("got compare %d != exp -1", c)
+	}
+	if !EqualT[any](nil, nil) {
+		t.Errorf("got nil interfaces unequal, exp equal")
+	}
+
+	type ids []int
+	s := []ids{{3, 2}, {1}, {}}
+	SortT(s)
+	if exp := []ids{{}, {1}, {2, 3}}; !EqualT(s, exp) {
+		t.Errorf("got %v != exp %v", s, exp)
+	}
+	ls := []tless{{3}, {1}, {2}}
+	SortT(ls)
+	if exp := []tless{{1}, {2}, {3}}; !EqualT(ls, exp) {
+		t.Errorf("got %v != exp %v", ls, exp)
+	}
+}
+
+func BenchmarkCompareT(b *testing.B) {
+	type l struct {
+		A int
+		B string
+	}
+	x, y := l{1, "a"}, l{1, "b"}
+	for b.Loop() {
+		CompareT(x, y)
+	}
+}
@@ -0,0 +1,225 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// Difference is a single location at which two values are not equal.
+//
+// Left and Right are the values at Path on each side. If the path only exists
+// on one side (a slice element past the end of the other slice, or a map key
+// missing from the other map), the missing side is nil.
+type Difference struct {
+	Path  Path
+	Left  any
+	Right any
+}
+
+// String returns the difference formatted as "path: left != right".
+func (d Difference) String() string {
+	p := d.Path
+	if p == "" {
+		p = "(root)"
+	}
+	return fmt.Sprintf("%s: %v != %v", p, d.Left, d.Right)
+}
+
+// Diff returns every location at which l and r are not equal, following the
+// same rules as Equal. The input values must have the same type, or this will
+// panic with a *TypeMismatchError. If the values are equal, this returns nil.
+//
+// Structs, slices, arrays, maps, pointers and interfaces are walked into so
+// that each difference is reported at the deepest path that differs. Slices
+// of different lengths report each common element that differs, and then each
+// element that exists only on one side. Maps report each key that exists on
+// only one side, and each common key whose value differs. Interfaces holding
+// different dynamic types, unordered slices (tagged as a set or with
+// UnorderedTypes), well known types, and types with comparison methods (see
+// Less) are reported as a whole.
+func Diff(l, r any) []Difference {
+	return DiffWith(l, r)
+}
+
+// DiffWith is like Diff, but with options that modify the comparison.
+func DiffWith(l, r any, opts ...Option) []Difference {
+	d := &differ{s: newState(opts)}
+	d.diff(reflect.ValueOf(l), reflect.ValueOf(r))
+	return d.ds
+}
+
+type differ struct {
+	s  *state
+	ds []Difference
+}
+
+func (d *differ) add(lv, rv reflect.Value) {
+	d.ds = append(d.ds, Difference{
+		Path:  d.s.curPath(),
+		Left:  valueInterface(lv),
+		Right: valueInterface(rv),
+	})
+}
+
+func valueInterface(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// enter pushes seg onto the path and returns whether the path is ignored.
+// The caller must pop the path.
+func (d *differ) enter(seg string) bool {
+	d.s.push(seg)
+	return d.s.o.paths && d.s.o.ignoredPath(d.s.path)
+}
+
+// leaf reports a difference at the current path if lv and rv are not equal.
+func (d *differ) leaf(t reflect.Type, lv, rv reflect.Value) {
+	if _, eq := compile(t).lteq(d.s, lv, rv); !eq {
+		d.add(lv, rv)
+	}
+}
+
+// empty records a difference between two empty slices or maps if only one is
+// nil and NilEmpty(NilBeforeEmpty) orders them.
+func (d *differ) empty(lv, rv reflect.Value) {
+	if _, eq := d.s.o.lteqEmpty(lv, rv); !eq {
+		d.add(lv, rv)
+	}
+}
+
+func (d *differ) diff(lv, rv reflect.Value) {
+	checkTypes(d.s.curPath(), lv, rv)
+	t := lv.Type()
+	if d.s.o.ignored(t) {
+		return
+	}
+	if d.s.o.comparer(t) != nil || methodsOf(t) != nil {
+		d.leaf(t, lv, rv)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for _, f := range fieldsOf(t) {
+			lf, rf := lv.Field(f.index), rv.Field(f.index)
+			d.s.push(f.seg)
+			switch {
+			case d.s.o.paths && d.s.o.ignoredField(d.s.path, f.sf):
+			case f.set:
+				if _, eq := lteqSet(d.s, f.typ, compile(f.typ.Elem()), lf, rf); !eq {
+					d.add(lf, rf)
+				}
+			default:
+				d.diff(lf, rf)
+			}
+			d.s.pop()
+		}
+
+	case reflect.Array,
+		reflect.Slice:
+		if d.s.o.unordered(t) {
+			d.leaf(t, lv, rv)
+			return
+		}
+		ll, lr := lv.Len(), rv.Len()
+		if ll == 0 && lr == 0 {
+			d.empty(lv, rv)
+			return
+		}
+		for i := range max(ll, lr) {
+			if !d.enter(indexSeg(i)) {
+				switch {
+				case i >= lr:
+					d.add(lv.Index(i), reflect.Value{})
+				case i >= ll:
+					d.add(reflect.Value{}, rv.Index(i))
+				default:
+					d.diff(lv.Index(i), rv.Index(i))
+				}
+			}
+			d.s.pop()
+		}
+
+	case reflect.Map:
+		if lv.Len() == 0 && rv.Len() == 0 {
+			d.empty(lv, rv)
+			return
+		}
+		// Keys are paired the same way Equal pairs them, in deep sorted
+		// order, rather than by identity: NaN keys and pointer keys to
+		// equal values are the same key.
+		key := compile(t.Key())
+		lents, rents := sortedEntries(d.s, key, lv), sortedEntries(d.s, key, rv)
+		for len(lents) > 0 || len(rents) > 0 {
+			var c int
+			switch {
+			case len(lents) == 0:
+				c = 1
+			case len(rents) == 0:
+				c = -1
+			default:
+				c = key.compare(d.s, lents[0].k, rents[0].k)
+			}
+			switch {
+			case c < 0:
+				if !d.enter(keySeg(lents[0].k)) {
+					d.add(lents[0].v, reflect.Value{})
+				}
+				lents = lents[1:]
+			case c > 0:
+				if !d.enter(keySeg(rents[0].k)) {
+					d.add(reflect.Value{}, rents[0].v)
+				}
+				rents = rents[1:]
+			default:
+				if !d.enter(keySeg(lents[0].k)) {
+					d.diff(lents[0].v, rents[0].v)
+				}
+				lents, rents = lents[1:], rents[1:]
+			}
+			d.s.pop()
+		}
+
+	case reflect.Pointer:
+		if lv.IsNil() || rv.IsNil() {
+			if lv.IsNil() != rv.IsNil() {
+				d.add(lv, rv)
+			}
+			return
+		}
+
+		lptr, rptr := unsafe.Pointer(lv.Pointer()), unsafe.Pointer(rv.Pointer())
+		if lptr == rptr {
+			return
+		}
+		lhas, rhas := d.s.p.hasOrAdd(lptr), d.s.p.hasOrAdd(rptr)
+		if !lhas {
+			defer d.s.p.remove(lptr)
+		}
+		if !rhas {
+			defer d.s.p.remove(rptr)
+		}
+
+		if lhas || rhas {
+			if lhas != rhas {
+				d.add(lv, rv)
+			}
+			return
+		}
+		d.diff(lv.Elem(), rv.Elem())
+
+	case reflect.Interface:
+		if lv.IsNil() || rv.IsNil() || lv.Elem().Type() != rv.Elem().Type() {
+			d.leaf(t, lv, rv)
+			return
+		}
+		d.diff(lv.Elem(), rv.Elem())
+
+	default:
+		d.leaf(t, lv, rv)
+	}
+}
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type inner struct {
+		Name string
+		n    int
+	}
+	type spec struct {
+		Replicas []inner
+		Labels   map[string]int
+		Owner    *inner
+	}
+	type config struct {
+		Spec spec
+	}
+
+	for _, test := range []struct {
+		l   any
+		r   any
+		exp []Difference
+	}{
+		{1, 1, nil},
+		{1, 2, []Difference{{"", 1, 2}}},
+		{[]int{1, 2}, []int{1, 2}, nil},
+		{[]int{1, 2, 3}, []int{1, 4}, []Difference{
+			{"[1]", 2, 4},
+			{"[2]", 3, nil},
+		}},
+		{[]int{1}, []int{1, 5}, []Difference{
+			{"[1]", nil, 5},
+		}},
+		{map[string]int{"a": 1, "b": 2}, map[string]int{"b": 3, "c": 4}, []Difference{
+			{`["a"]`, 1, nil},
+			{`["b"]`, 2, 3},
+			{`["c"]`, nil, 4},
+		}},
+		{newRecursive2(1), newRecursive2(1), nil},
+
+		// Map keys are paired as Equal pairs them, not by identity.
+		{map[float64]int{math.NaN(): 1}, map[float64]int{math.NaN(): 1}, nil},
+		{map[float64]int{math.NaN(): 1}, map[float64]int{math.NaN(): 2}, []Difference{
+			{"[NaN]", 1, 2},
+		}},
+		{map[*int]string{new(int): "a"}, map[*int]string{new(int): "a"}, nil},
+
+		{
+			config{spec{
+				Replicas: []inner{{"a", 1}, {"b", 2}},
+				Labels:   map[string]int{"x": 1},
+				Owner:    &inner{"o", 1},
+			}},
+			config{spec{
+				Replicas: []inner{{"a", 9}, {"c", 2}},
+				Labels:   map[string]int{"x": 1},
+				Owner:    nil,
+			}},
+			[]Difference{
+				{".Spec.Replicas[1].Name", "b", "c"},
+				{".Spec.Owner", &inner{"o", 1}, (*inner)(nil)},
+			},
+		},
+
+		//
+	} {
+		got := Diff(test.l, test.r)
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("l %v r %v, got diff %v != exp %v", test.l, test.r, got, test.exp)
+		}
+		if eq := Equal(test.l, test.r); eq != (len(got) == 0) {
+			t.Errorf("l %v r %v, got equal? %v, but diff %v", test.l, test.r, eq, got)
+		}
+	}
+}
+
+func TestDifferenceString(t *testing.T) {
+	for _, test := range []struct {
+		d   Difference
+		exp string
+	}{
+		{Difference{"", 1, 2}, "(root): 1 != 2"},
+		{Difference{".A[3].B", "x", nil}, ".A[3].B: x != <nil>"},
+	} {
+		if got := test.d.String(); got != test.exp {
+			t.Errorf("got %q != exp %q", got, test.exp)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypeMismatchError is returned when two values of different types are
+// compared.
+type TypeMismatchError struct {
+	// Path is where the mismatch was found; the empty path is the root.
+	Path Path
+	// Left and Right are the types of the two values. A type is nil if
+	// its value was an untyped nil.
+	Left, Right reflect.Type
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("types: cannot compare %v with %v%s", e.Left, e.Right, at(e.Path))
+}
+
+// UncomparableError is returned when a value cannot be compared or sorted at
+// all, such as an untyped nil.
+type UncomparableError struct {
+	// Path is where the value was found; the empty path is the root.
+	Path Path
+	// Type is the type of the value, or nil for an untyped nil.
+	Type reflect.Type
+}
+
+func (e *UncomparableError) Error() string {
+	return fmt.Sprintf("types: cannot compare value of type %v%s", e.Type, at(e.Path))
+}
+
+func at(p Path) string {
+	if p == "" {
+		return ""
+	}
+	return " at " + string(p)
+}
+
+// checkTypes panics with a *TypeMismatchError or *UncomparableError if lv and
+// rv cannot be compared against each other.
+func checkTypes(path Path, lv, rv reflect.Value) {
+	if !lv.IsValid() || !rv.IsValid() {
+		if !lv.IsValid() && !rv.IsValid() {
+			panic(&UncomparableError{Path: path})
+		}
+		panic(&TypeMismatchError{Path: path, Left: typeOf(lv), Right: typeOf(rv)})
+	}
+	if lt, rt := lv.Type(), rv.Type(); lt != rt {
+		panic(&TypeMismatchError{Path: path, Left: lt, Right: rt})
+	}
+}
+
+func typeOf(v reflect.Value) reflect.Type {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Type()
+}
+
+// TryCompare is like CompareWith, but returns an error rather than panicking
+// if l and r cannot be compared.
+func TryCompare(l, r any, opts ...Option) (c int, err error) {
+	defer recoverError(&err)
+	return CompareWith(l, r, opts...), nil
+}
+
+// TryEqual is like EqualWith, but returns an error rather than panicking if l
+// and r cannot be compared.
+func TryEqual(l, r any, opts ...Option) (eq bool, err error) {
+	defer recoverError(&err)
+	return EqualWith(l, r, opts...), nil
+}
+
+// TrySort is like SortWith, but returns an error rather than panicking if s
+// is an untyped nil or contains values that cannot be compared.
+func TrySort(s any, opts ...Option) (err error) {
+	defer recoverError(&err)
+	SortWith(s, opts...)
+	return nil
+}
+
+// recoverError recovers a panic of one of this package's errors into err.
+// Any other panic is a bug (or a panic from a user method) and is repanicked.
+func recoverError(err *error) {
+	switch r := recover().(type) {
+	case nil:
+	case *TypeMismatchError:
+		*err = r
+	case *UncomparableError:
+		*err = r
+	default:
+		panic(r)
+	}
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTryErrors(t *testing.T) {
+	var mismatch *TypeMismatchError
+	var uncomparable *UncomparableError
+
+	if _, err := TryCompare(1, "a"); !errors.As(err, &mismatch) {
+		t.Errorf("got err %v, exp type mismatch", err)
+	} else if mismatch.Left != reflect.TypeFor[int]() || mismatch.Right != reflect.TypeFor[string]() {
+		t.Errorf("got mismatch %v, exp int vs string", mismatch)
+	}
+	if _, err := TryEqual(nil, 1); !errors.As(err, &mismatch) {
+		t.Errorf("got err %v, exp type mismatch", err)
+	}
+	if _, err := TryEqual(nil, nil); !errors.As(err, &uncomparable) {
+		t.Errorf("got err %v, exp uncomparable", err)
+	}
+	if err := TrySort(nil); !errors.As(err, &uncomparable) {
+		t.Errorf("got err %v, exp uncomparable", err)
+	}
+
+	if c, err := TryCompare([]int{1}, []int{2}); err != nil || c != -1 {
+		t.Errorf("got %d, %v, exp -1, nil", c, err)
+	}
+	if eq, err := TryEqual([]any{[]int{1}}, []any{[]int{1}}); err != nil || !eq {
+		t.Errorf("got %v, %v, exp true, nil", eq, err)
+	}
+	s := []int{3, 1, 2}
+	if err := TrySort(s); err != nil || !reflect.DeepEqual(s, []int{1, 2, 3}) {
+		t.Errorf("got %v, %v, exp sorted, nil", s, err)
+	}
+
+	// Maps whose keys cannot be looked up are still compared.
+	nan := math.NaN()
+	if eq, err := TryEqual(map[float64]int{nan: 1}, map[float64]int{nan: 1}); err != nil || !eq {
+		t.Errorf("got %v, %v, exp true, nil", eq, err)
+	}
+	a, b := 1, 1
+	if eq, err := TryEqual(map[*int]int{&a: 1}, map[*int]int{&b: 1}); err != nil || !eq {
+		t.Errorf("got %v, %v, exp true, nil", eq, err)
+	}
+}
+
+func TestTryErrorsRepanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected non-package panic to propagate")
+		}
+	}()
+	TryEqual(struct {
+		A int `types:"bogus"`
+	}{}, struct {
+		A int `types:"bogus"`
+	}{})
+}
+
+func TestErrorStrings(t *testing.T) {
+	for _, test := range []struct {
+		err error
+		exp string
+	}{
+		{&TypeMismatchError{Left: reflect.TypeFor[int](), Right: reflect.TypeFor[string]()}, "types: cannot compare int with string"},
+		{&TypeMismatchError{Path: ".A", Right: reflect.TypeFor[string]()}, "types: cannot compare <nil> with string at .A"},
+		{&UncomparableError{}, "types: cannot compare value of type <nil>"},
+	} {
+		if got := test.err.Error(); got != test.exp {
+			t.Errorf("got %q != exp %q", got, test.exp)
+		}
+	}
+}
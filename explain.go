@@ -0,0 +1,126 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Explanation describes why CompareExplain returned what it did: the first
+// path at which the values were not equal, and the rule that ordered them
+// there.
+//
+// Rules are short descriptions of the ordering rules documented on Less, such
+// as "slice length 3 < 5", "nil pointer < non-nil", "map key "a" < "b"", or
+// "NaN < 1.5". The zero Explanation means the values are equal.
+type Explanation struct {
+	Path Path
+	Rule string
+}
+
+// String returns the explanation formatted as "rule at path", or "equal".
+func (e Explanation) String() string {
+	if e.Rule == "" {
+		return "equal"
+	}
+	p := e.Path
+	if p == "" {
+		p = "(root)"
+	}
+	return e.Rule + " at " + string(p)
+}
+
+// CompareExplain is like Compare, but also explains the result.
+func CompareExplain(l, r any) (int, Explanation) {
+	return CompareExplainWith(l, r)
+}
+
+// CompareExplainWith is like CompareExplain, but with options that modify the
+// comparison.
+func CompareExplainWith(l, r any, opts ...Option) (int, Explanation) {
+	// Explaining needs paths. The options may be shared, so we track
+	// paths in a copy.
+	o := *newOptions(opts)
+	o.paths = true
+	s := &state{o: &o, ex: new(Explanation)}
+	c := s.compareValues(reflect.ValueOf(l), reflect.ValueOf(r))
+	if c == 0 {
+		return 0, Explanation{}
+	}
+	return c, *s.ex
+}
+
+// explaining returns whether the next decision should be explained: we are
+// explaining, nothing has been explained yet, and we are not within a
+// comparison that does not decide anything (such as sorting map keys).
+func (s *state) explaining() bool {
+	return s.ex != nil && s.quiet == 0 && s.ex.Rule == ""
+}
+
+// explain records the rule for the current path. This must only be called if
+// explaining returns true.
+func (s *state) explain(format string, args ...any) {
+	s.ex.Path = s.curPath()
+	s.ex.Rule = fmt.Sprintf(format, args...)
+}
+
+// explainNil explains an ordering of nil against non-nil what.
+func (s *state) explainNil(lt bool, what string) {
+	if lt {
+		s.explain("nil %s < non-nil", what)
+	} else {
+		s.explain("non-nil %s > nil", what)
+	}
+}
+
+// explainValues explains an ordering decided by comparing lv and rv as a
+// whole. The prefix, if any, precedes the values.
+func (s *state) explainValues(lt bool, prefix string, lv, rv reflect.Value) {
+	s.explain("%s%s %s %s", prefix, formatValue(lv), op(lt), formatValue(rv))
+}
+
+// op returns the operator for a decided ordering.
+func op(lt bool) string {
+	if lt {
+		return "<"
+	}
+	return ">"
+}
+
+func formatValue(v reflect.Value) string {
+	switch {
+	case v.Kind() == reflect.String:
+		return strconv.Quote(v.String())
+	case v.CanInterface():
+		return fmt.Sprint(v.Interface())
+	}
+	return v.Type().String()
+}
+
+// explained explains lt and eq as a comparison of lv and rv as a whole if the
+// values are not equal, and returns lt and eq.
+func explained(s *state, lt, eq bool, lv, rv reflect.Value) (bool, bool) {
+	if !eq && s.explaining() {
+		s.explainValues(lt, "", lv, rv)
+	}
+	return lt, eq
+}
+
+// lteqComparer compares lv and rv with a comparer.
+func lteqComparer(s *state, cmp comparer, lv, rv reflect.Value) (lt, eq bool) {
+	c := cmp(lv, rv)
+	return explained(s, c < 0, c == 0, lv, rv)
+}
+
+// lteqNil compares two pointers or interfaces (what) at least one of which is
+// nil.
+func lteqNil(s *state, what string, lnil, rnil bool) (lt, eq bool) {
+	lt, eq = lnil && !rnil, lnil && rnil
+	if !eq {
+		lt = s.nilLt(lnil)
+	}
+	if !eq && s.explaining() {
+		s.explainNil(lt, what)
+	}
+	return lt, eq
+}
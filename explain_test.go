@@ -0,0 +1,121 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareExplain(t *testing.T) {
+	type owner struct {
+		Name string
+	}
+	type item struct {
+		Name  string
+		Score float64
+	}
+	type doc struct {
+		Items  []item
+		Owner  *owner
+		Labels map[string]int
+		Any    any
+		Rank   int      `types:"desc"`
+		Tags   []string `types:"set"`
+	}
+
+	for _, test := range []struct {
+		l, r any
+		opts []Option
+		exp  int
+		ex   string
+	}{
+		{doc{}, doc{}, nil, 0, "equal"},
+		{
+			doc{Items: make([]item, 3)},
+			doc{Items: make([]item, 5)},
+			nil, -1, "slice length 3 < 5 at .Items",
+		},
+		{
+			doc{Owner: nil},
+			doc{Owner: &owner{}},
+			nil, -1, "nil pointer < non-nil at .Owner",
+		},
+		{
+			doc{Owner: &owner{"b"}},
+			doc{Owner: &owner{"a"}},
+			nil, 1, `"b" > "a" at .Owner.Name`,
+		},
+		{
+			doc{Items: []item{{"a", math.NaN()}}},
+			doc{Items: []item{{"a", 1.5}}},
+			nil, -1, "NaN < 1.5 at .Items[0].Score",
+		},
+		{
+			doc{Labels: map[string]int{"a": 1}},
+			doc{Labels: map[string]int{"a": 1, "b": 1}},
+			nil, -1, "map length 1 < 2 at .Labels",
+		},
+		{
+			doc{Labels: map[string]int{"a": 1, "c": 1}},
+			doc{Labels: map[string]int{"a": 1, "b": 1}},
+			nil, 1, `map key "c" > "b" at .Labels`,
+		},
+		{
+			doc{Labels: map[string]int{"a": 1, "b": 2}},
+			doc{Labels: map[string]int{"a": 1, "b": 1}},
+			nil, 1, `2 > 1 at .Labels["b"]`,
+		},
+		{
+			doc{Labels: map[string]int{}},
+			doc{Labels: nil},
+			[]Option{NilEmpty(NilBeforeEmpty)}, 1, "non-nil map > nil at .Labels",
+		},
+		{
+			doc{Any: 1},
+			doc{Any: "a"},
+			nil, -1, "type int < string at .Any",
+		},
+		{
+			doc{Rank: 1},
+			doc{Rank: 2},
+			nil, 1, "1 < 2 (desc) at .Rank",
+		},
+		{
+			doc{Tags: []string{"b", "a"}},
+			doc{Tags: []string{"c", "a"}},
+			nil, -1, `"b" < "c" at .Tags[*]`,
+		},
+		{
+			doc{Tags: []string{"b"}},
+			doc{Tags: []string{"c", "a"}},
+			nil, -1, "set length 1 < 2 at .Tags",
+		},
+		{
+			[]int{1, 2},
+			[]int{1, 3, 0},
+			[]Option{Lexicographic()}, -1, "2 < 3 at [1]",
+		},
+		{
+			[]int{1, 2},
+			[]int{1, 2, 0},
+			[]Option{Lexicographic()}, -1, "slice length 2 < 3 at (root)",
+		},
+		{
+			[]int{1, 2},
+			[]int{1, 3},
+			[]Option{Descending()}, 1, "2 < 3 (desc) at [1]",
+		},
+		{
+			tcompare{2},
+			tcompare{1},
+			nil, 1, "Compare method: {2} > {1} at (root)",
+		},
+	} {
+		c, ex := CompareExplainWith(test.l, test.r, test.opts...)
+		if c != test.exp || ex.String() != test.ex {
+			t.Errorf("explain %v vs %v: got %d, %q != exp %d, %q", test.l, test.r, c, ex, test.exp, test.ex)
+		}
+		if exp := CompareWith(test.l, test.r, test.opts...); c != exp {
+			t.Errorf("explain %v vs %v: got %d != Compare %d", test.l, test.r, c, exp)
+		}
+	}
+}
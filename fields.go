@@ -0,0 +1,99 @@
+package types
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// field is an exported struct field that takes part in comparisons, along
+// with the options parsed from its types struct tag.
+type field struct {
+	index int
+	name  string
+	seg   string // the path segment for this field
+	sf    reflect.StructField
+	typ   reflect.Type
+	order int // explicit priority; only meaningful if ordered
+	desc  bool
+	set   bool
+
+	ordered bool
+}
+
+var structFields sync.Map // reflect.Type => []field
+
+// fieldsOf returns the exported, non-ignored fields of the struct type t in
+// the order they are compared: fields with an explicit order first, sorted by
+// that order, followed by the remaining fields in declaration order.
+func fieldsOf(t reflect.Type) []field {
+	if fs, ok := structFields.Load(t); ok {
+		return fs.([]field)
+	}
+
+	var fs []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		f, skip := parseField(t, sf)
+		if skip {
+			continue
+		}
+		f.index = i
+		fs = append(fs, f)
+	}
+	slices.SortStableFunc(fs, func(l, r field) int {
+		switch {
+		case l.ordered && r.ordered:
+			return cmp.Compare(l.order, r.order)
+		case l.ordered:
+			return -1
+		case r.ordered:
+			return 1
+		}
+		return 0
+	})
+
+	structFields.Store(t, fs)
+	return fs
+}
+
+// parseField parses the types struct tag of sf, returning whether the field
+// is ignored. An invalid tag is a programming error and panics.
+func parseField(t reflect.Type, sf reflect.StructField) (f field, skip bool) {
+	f = field{name: sf.Name, seg: fieldSeg(sf.Name), sf: sf, typ: sf.Type}
+	tag, ok := sf.Tag.Lookup("types")
+	if !ok || tag == "" {
+		return f, false
+	}
+	if tag == "-" {
+		return f, true
+	}
+
+	for opt := range strings.SplitSeq(tag, ",") {
+		switch opt = strings.TrimSpace(opt); {
+		case opt == "desc":
+			f.desc = true
+		case opt == "set":
+			if k := sf.Type.Kind(); k != reflect.Slice && k != reflect.Array {
+				panic(fmt.Sprintf("types: invalid struct tag on %s.%s: set requires a slice or array, not %s", t, sf.Name, k))
+			}
+			f.set = true
+		case strings.HasPrefix(opt, "order="):
+			n, err := strconv.Atoi(strings.TrimPrefix(opt, "order="))
+			if err != nil {
+				panic(fmt.Sprintf("types: invalid struct tag on %s.%s: invalid order: %v", t, sf.Name, err))
+			}
+			f.order, f.ordered = n, true
+		default:
+			panic(fmt.Sprintf("types: invalid struct tag on %s.%s: unknown option %q", t, sf.Name, opt))
+		}
+	}
+	return f, false
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+type tagged struct {
+	Name     string
+	Priority int      `types:"order=1"`
+	Age      int      `types:"desc"`
+	Group    int      `types:"order=0"`
+	Skip     []int    `types:"-"`
+	Labels   []string `types:"set"`
+}
+
+func TestStructTags(t *testing.T) {
+	for _, test := range []struct {
+		l     tagged
+		r     tagged
+		less  bool
+		equal bool
+	}{
+		// Ignored fields never matter.
+		{tagged{Skip: []int{1}}, tagged{Skip: []int{2, 3}}, false, true},
+
+		// Group (order=0) beats Priority (order=1) beats Name.
+		{tagged{Name: "b", Priority: 1, Group: 0}, tagged{Name: "a", Priority: 0, Group: 1}, true, false},
+		{tagged{Name: "a", Priority: 1}, tagged{Name: "b", Priority: 0}, false, false},
+		{tagged{Name: "a"}, tagged{Name: "b"}, true, false},
+
+		// Age is descending.
+		{tagged{Age: 3}, tagged{Age: 2}, true, false},
+		{tagged{Age: 2}, tagged{Age: 3}, false, false},
+
+		// Labels ignore order, but still compare by length and contents.
+		{tagged{Labels: []string{"b", "a"}}, tagged{Labels: []string{"a", "b"}}, false, true},
+		{tagged{Labels: []string{"c", "a"}}, tagged{Labels: []string{"b", "a"}}, false, false},
+		{tagged{Labels: []string{"c"}}, tagged{Labels: []string{"b", "a"}}, true, false},
+
+		//
+	} {
+		lt, eq := Less(test.l, test.r), Equal(test.l, test.r)
+		if lt != test.less {
+			t.Errorf("l %v r %v, got less? %v, exp less? %v", test.l, test.r, lt, test.less)
+		}
+		if eq != test.equal {
+			t.Errorf("l %v r %v, got equal? %v, exp equal? %v", test.l, test.r, eq, test.equal)
+		}
+	}
+}
+
+func TestStructTagsSort(t *testing.T) {
+	in := []tagged{
+		{Name: "a", Age: 1, Skip: []int{3, 2}},
+		{Name: "a", Age: 5},
+		{Name: "b", Group: -1, Labels: []string{"z", "y"}},
+	}
+	exp := []tagged{
+		{Name: "b", Group: -1, Labels: []string{"y", "z"}},
+		{Name: "a", Age: 5},
+		{Name: "a", Age: 1, Skip: []int{3, 2}},
+	}
+	Sort(in)
+	if !reflect.DeepEqual(in, exp) {
+		t.Errorf("got %v != exp %v", in, exp)
+	}
+
+	d := Diff(tagged{Labels: []string{"a"}, Skip: []int{1}}, tagged{Labels: []string{"b"}})
+	if !reflect.DeepEqual(d, []Difference{{".Labels", []string{"a"}, []string{"b"}}}) {
+		t.Errorf("got unexpected diff %v", d)
+	}
+}
+
+func TestStructTagsInvalid(t *testing.T) {
+	for _, v := range []any{
+		struct {
+			A int `types:"set"`
+		}{},
+		struct {
+			A int `types:"order=x"`
+		}{},
+		struct {
+			A int `types:"bogus"`
+		}{},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%T: expected panic on invalid tag", v)
+				}
+			}()
+			Equal(v, v)
+		}()
+	}
+}
@@ -0,0 +1,258 @@
+package types
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+var defaultSeed = maphash.MakeSeed()
+
+// Hash returns a deep hash of v that is consistent with Equal: if Equal(a, b)
+// is true, then Hash(a) == Hash(b). The hash is seeded randomly once per
+// process, so it must not be persisted; use HashSeed to control the seed.
+//
+// Hashing follows the same rules as Equal: only exported fields are hashed,
+// all NaNs hash the same, and maps and set tagged fields hash independently
+// of their order. Pointers are only followed to a fixed depth, so that
+// recursive values can be hashed; values that differ only deeper than that
+// hash the same.
+//
+// Types that have Compare, Less, or Equal methods (see Less) can consider
+// values equal that have different contents, so their contents are not
+// hashed. Values of such types only contribute their presence to the hash.
+// The same is true of types with comparers (see RegisterComparer). The well
+// known types that Less documents are hashed by their value.
+func Hash(v any) uint64 {
+	return HashSeedWith(defaultSeed, v)
+}
+
+// HashSeed is like Hash, but uses the given seed.
+func HashSeed(seed maphash.Seed, v any) uint64 {
+	return HashSeedWith(seed, v)
+}
+
+// HashWith is like Hash, but consistent with EqualWith and the given options:
+// if EqualWith(a, b, opts...) is true, then HashWith(a, opts...) ==
+// HashWith(b, opts...). Options that make floats approximately equal cannot
+// be hashed consistently, so with any float tolerance, floats are not hashed.
+func HashWith(v any, opts ...Option) uint64 {
+	return HashSeedWith(defaultSeed, v, opts...)
+}
+
+// HashSeedWith is like HashWith, but uses the given seed.
+func HashSeedWith(seed maphash.Seed, v any, opts ...Option) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return h.Sum64()
+	}
+	x := &hashState{s: newState(opts), seed: seed}
+	x.hash(&h, rv)
+	return h.Sum64()
+}
+
+// maxHashDepth is how many pointers deep Hash follows pointers.
+const maxHashDepth = 8
+
+type hashState struct {
+	s     *state
+	seed  maphash.Seed
+	depth int // pointers followed to the current value
+}
+
+func writeUint64(h *maphash.Hash, u uint64) {
+	h.Write(binary.LittleEndian.AppendUint64(nil, u))
+}
+
+func writeFloat(h *maphash.Hash, f float64) {
+	switch {
+	case math.IsNaN(f):
+		f = math.NaN()
+	case f == 0:
+		f = 0 // -0 equals 0
+	}
+	writeUint64(h, math.Float64bits(f))
+}
+
+// unordered hashes each value returned by each independently and writes the
+// sum of the hashes to h, so that the order of values does not matter.
+func (x *hashState) unordered(h *maphash.Hash, n int, each func(h *maphash.Hash, i int)) {
+	var sum uint64
+	var sub maphash.Hash
+	for i := range n {
+		sub.SetSeed(x.seed)
+		each(&sub, i)
+		sum += sub.Sum64()
+	}
+	writeUint64(h, uint64(n))
+	writeUint64(h, sum)
+}
+
+// empty writes whether v is nil if v is an empty slice or map and nil is
+// distinct from empty.
+func (x *hashState) empty(h *maphash.Hash, v reflect.Value) {
+	if x.s.o.nilMode == NilBeforeEmpty && v.Kind() != reflect.Array && v.Len() == 0 && v.IsNil() {
+		h.WriteByte(0)
+	}
+}
+
+// hashAt hashes v at the path segment seg, skipping v if the path is
+// ignored. If paths are not tracked, this is the same as hash.
+func (x *hashState) hashAt(h *maphash.Hash, seg string, v reflect.Value) {
+	if !x.s.o.paths {
+		x.hash(h, v)
+		return
+	}
+	x.s.push(seg)
+	defer x.s.pop()
+	if !x.s.o.ignoredPath(x.s.path) {
+		x.hash(h, v)
+	}
+}
+
+func (x *hashState) hash(h *maphash.Hash, v reflect.Value) {
+	t := v.Type()
+	if x.s.o.ignored(t) {
+		return
+	}
+	if x.s.o.custom(t) != nil {
+		return
+	}
+	if hash := knownHasher(t); hash != nil {
+		hash(h, v)
+		return
+	}
+	if methodsOf(t) != nil && callable(v, v) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.WriteByte(1)
+		} else {
+			h.WriteByte(0)
+		}
+	case reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64:
+		writeUint64(h, uint64(v.Int()))
+	case reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		writeUint64(h, v.Uint())
+	case reflect.Float32,
+		reflect.Float64:
+		if x.s.o.tolerant {
+			return
+		}
+		writeFloat(h, v.Float())
+	case reflect.Complex64,
+		reflect.Complex128:
+		if x.s.o.tolerant {
+			return
+		}
+		c := v.Complex()
+		writeFloat(h, real(c))
+		writeFloat(h, imag(c))
+	case reflect.Chan:
+		writeUint64(h, uint64(v.Len()))
+	case reflect.Func,
+		reflect.UnsafePointer:
+		writeUint64(h, uint64(v.Pointer()))
+	case reflect.String:
+		writeUint64(h, uint64(v.Len()))
+		h.WriteString(v.String())
+
+	case reflect.Interface:
+		if v.IsNil() {
+			h.WriteByte(0)
+			return
+		}
+		h.WriteByte(1)
+		v = v.Elem()
+		h.WriteString(v.Type().String())
+		x.hash(h, v)
+
+	case reflect.Struct:
+		for _, f := range fieldsOf(t) {
+			fv := v.Field(f.index)
+			if x.s.o.paths {
+				x.s.push(f.seg)
+				ignored := x.s.o.ignoredField(x.s.path, f.sf)
+				if !ignored {
+					x.field(h, f, fv)
+				}
+				x.s.pop()
+				continue
+			}
+			x.field(h, f, fv)
+		}
+
+	case reflect.Array,
+		reflect.Slice:
+		if x.s.o.unordered(t) {
+			x.empty(h, v)
+			x.unordered(h, v.Len(), func(h *maphash.Hash, i int) { x.hashAt(h, anySeg, v.Index(i)) })
+			return
+		}
+		x.empty(h, v)
+		writeUint64(h, uint64(v.Len()))
+		for i := range v.Len() {
+			if x.s.o.paths {
+				x.hashAt(h, indexSeg(i), v.Index(i))
+			} else {
+				x.hash(h, v.Index(i))
+			}
+		}
+
+	case reflect.Map:
+		x.empty(h, v)
+		iter := v.MapRange()
+		x.unordered(h, v.Len(), func(h *maphash.Hash, _ int) {
+			iter.Next()
+			x.hash(h, iter.Key())
+			if x.s.o.paths {
+				x.hashAt(h, keySeg(iter.Key()), iter.Value())
+			} else {
+				x.hash(h, iter.Value())
+			}
+		})
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			h.WriteByte(0)
+			return
+		}
+		// Equal considers identical pointers equal without walking
+		// them, so the hash cannot depend on which pointers are being
+		// walked. Instead, we hash a fixed depth of pointers, which is
+		// the same for any path to the same memory and ends cycles.
+		if x.depth == maxHashDepth {
+			h.WriteByte(2)
+			return
+		}
+		x.depth++
+		defer func() { x.depth-- }()
+		h.WriteByte(1)
+		x.hash(h, v.Elem())
+	}
+}
+
+// field hashes the struct field fv.
+func (x *hashState) field(h *maphash.Hash, f field, fv reflect.Value) {
+	if f.set && !x.s.o.ignored(f.typ) {
+		x.empty(h, fv)
+		x.unordered(h, fv.Len(), func(h *maphash.Hash, i int) { x.hashAt(h, anySeg, fv.Index(i)) })
+		return
+	}
+	x.hash(h, fv)
+}
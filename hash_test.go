@@ -0,0 +1,98 @@
+package types
+
+import (
+	"hash/maphash"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestHash(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	type inner struct {
+		A int
+		b int
+	}
+	type node struct {
+		V          int
+		Next, Prev *node
+	}
+	// Values sharing nodes in a cycle: a2 and a are equal because both
+	// point to b, though b points back to a.
+	a, b := &node{V: 1}, &node{V: 2}
+	a.Next, b.Prev = b, a
+	a2 := &node{V: 1, Next: b}
+	self := &node{V: 1}
+	self.Next = self
+	for _, test := range []struct {
+		l     any
+		r     any
+		equal bool
+	}{
+		{1, 1, true},
+		{1, 2, false},
+		{"ab", "ab", true},
+		{[2]string{"ab", "c"}, [2]string{"a", "bc"}, false},
+		{math.NaN(), math.NaN(), true},
+		{math.Copysign(0, -1), 0.0, true},
+		{complex(math.NaN(), 1), complex(math.NaN(), 1), true},
+
+		{inner{1, 2}, inner{1, 3}, true},
+		{inner{1, 2}, inner{2, 2}, false},
+		{[]int{1, 2}, []int{1, 2}, true},
+		{[]int{1, 2}, []int{2, 1}, false},
+		{map[string]int{"a": 1, "b": 2, "c": 3}, map[string]int{"c": 3, "b": 2, "a": 1}, true},
+		{map[string]int{"a": 1, "b": 2}, map[string]int{"a": 2, "b": 1}, false},
+
+		{tagged{Labels: []string{"a", "b"}, Skip: []int{1}}, tagged{Labels: []string{"b", "a"}}, true},
+		{tagged{Labels: []string{"a", "b"}}, tagged{Labels: []string{"a", "c"}}, false},
+
+		{[]any{1, "a", nil}, []any{1, "a", nil}, true},
+		{[]any{1}, []any{int8(1)}, false},
+
+		{&inner{1, 2}, &inner{1, 5}, true},
+		{(*inner)(nil), &inner{}, false},
+		{newRecursive(3), newRecursive(3), true},
+		{newRecursive2(2), newRecursive2(2), true},
+
+		{t0, t0.In(time.FixedZone("x", 3600)), true},
+		{t0, t0.Add(1), false},
+		{big.NewInt(5), big.NewInt(5), true},
+		{*new(big.Float).SetPrec(20).SetInt64(3), *new(big.Float).SetPrec(200).SetInt64(3), true},
+		{big.NewRat(1, 2), big.NewRat(2, 4), true},
+
+		// Types with methods can be equal with different contents.
+		{tequal{1, 1}, tequal{2, 1}, true},
+		{tless{1}, tless{1}, true},
+
+		{a, a2, true},
+		{self, &node{V: 1, Next: self}, true},
+		{a, &node{V: 1, Next: &node{V: 3}}, false},
+	} {
+		if eq := Equal(test.l, test.r); eq != test.equal {
+			t.Fatalf("l %v r %v, got equal? %v, exp equal? %v", test.l, test.r, eq, test.equal)
+		}
+		lh, rh := Hash(test.l), Hash(test.r)
+		if test.equal && lh != rh {
+			t.Errorf("l %v r %v, equal but got different hashes %x != %x", test.l, test.r, lh, rh)
+		}
+		if !test.equal && lh == rh {
+			t.Errorf("l %v r %v, unequal but got same hash %x", test.l, test.r, lh)
+		}
+	}
+}
+
+func TestHashSeed(t *testing.T) {
+	seed := maphash.MakeSeed()
+	v := map[string][]int{"a": {1, 2}, "b": {3}}
+	if HashSeed(seed, v) != HashSeed(seed, v) {
+		t.Errorf("got different hashes for the same seed")
+	}
+	if HashSeed(seed, v) == HashSeed(maphash.MakeSeed(), v) {
+		t.Errorf("got the same hash for different seeds")
+	}
+	if Hash(nil) != Hash(nil) {
+		t.Errorf("got different hashes for nil")
+	}
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestInterfaces(t *testing.T) {
+	type holder struct {
+		V any
+	}
+	for _, test := range []struct {
+		l     any
+		r     any
+		less  bool
+		equal bool
+	}{
+		{holder{nil}, holder{nil}, false, true},
+		{holder{nil}, holder{1}, true, false},
+		{holder{1}, holder{nil}, false, false},
+
+		{holder{1}, holder{2}, true, false},
+		{holder{2}, holder{2}, false, true},
+
+		// Uncomparable dynamic values are compared deeply.
+		{holder{[]int{1}}, holder{[]int{1}}, false, true},
+		{holder{[]int{1}}, holder{[]int{2}}, true, false},
+		{holder{map[string]any{"a": []any{1.0}}}, holder{map[string]any{"a": []any{1.0}}}, false, true},
+
+		// Different dynamic types are ordered by type name.
+		{holder{1}, holder{"a"}, true, false},    // int < string
+		{holder{"a"}, holder{1.0}, false, false}, // string > float64
+		{holder{int8(9)}, holder{int(1)}, false, false},
+
+		{[]any{1, "a"}, []any{1, "b"}, true, false},
+
+		// Functions compare by pointer, rather than panicking.
+		{holder{TestInterfaces}, holder{TestInterfaces}, false, true},
+
+		//
+	} {
+		lt, eq := Less(test.l, test.r), Equal(test.l, test.r)
+		if lt != test.less {
+			t.Errorf("l %v r %v, got less? %v, exp less? %v", test.l, test.r, lt, test.less)
+		}
+		if eq != test.equal {
+			t.Errorf("l %v r %v, got equal? %v, exp equal? %v", test.l, test.r, eq, test.equal)
+		}
+	}
+}
+
+func TestInterfacesJSON(t *testing.T) {
+	var l, r map[string]any
+	if err := json.Unmarshal([]byte(`{"a": [1, "x", {"b": null}], "c": 2}`), &l); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"c": 2, "a": [1, "y", {"b": null}]}`), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if Equal(l, r) {
+		t.Errorf("got equal, exp unequal")
+	}
+	if !Less(l, r) {
+		t.Errorf("got not less, exp less")
+	}
+	if d := Diff(l, r); !reflect.DeepEqual(d, []Difference{{`["a"][1]`, "x", "y"}}) {
+		t.Errorf("got unexpected diff %v", d)
+	}
+
+	s := []any{"b", 2.0, nil, "a", 1.0}
+	Sort(s)
+	if exp := []any{nil, 1.0, 2.0, "a", "b"}; !reflect.DeepEqual(s, exp) {
+		t.Errorf("got %v != exp %v", s, exp)
+	}
+}
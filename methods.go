@@ -0,0 +1,94 @@
+package types
+
+import (
+	"reflect"
+	"sync"
+)
+
+// methods holds the indices of the comparison methods a type has, or -1 for
+// each method the type does not have.
+type methods struct {
+	compare int // Compare(T) int
+	less    int // Less(T) bool
+	equal   int // Equal(T) bool
+}
+
+var typeMethods sync.Map // reflect.Type => *methods
+
+// methodsOf returns the comparison methods of t, or nil if t has none.
+func methodsOf(t reflect.Type) *methods {
+	if m, ok := typeMethods.Load(t); ok {
+		return m.(*methods)
+	}
+
+	m := &methods{
+		compare: methodIndex(t, "Compare", reflect.TypeFor[int]()),
+		less:    methodIndex(t, "Less", reflect.TypeFor[bool]()),
+		equal:   methodIndex(t, "Equal", reflect.TypeFor[bool]()),
+	}
+	if m.compare < 0 && m.less < 0 && m.equal < 0 {
+		m = nil
+	}
+	typeMethods.Store(t, m)
+	return m
+}
+
+// methodIndex returns the index of the method name on t if the method accepts
+// a single t and returns a single out, or -1.
+func methodIndex(t reflect.Type, name string, out reflect.Type) int {
+	meth, ok := t.MethodByName(name)
+	if !ok {
+		return -1
+	}
+	mt := meth.Type
+	in := 0
+	if t.Kind() != reflect.Interface {
+		in = 1 // skip the receiver
+	}
+	if mt.NumIn() != in+1 || mt.NumOut() != 1 || mt.In(in) != t || mt.Out(0) != out {
+		return -1
+	}
+	return meth.Index
+}
+
+// orders returns whether the methods define an ordering, rather than only
+// equality.
+func (m *methods) orders() bool {
+	return m != nil && (m.compare >= 0 || m.less >= 0)
+}
+
+func callMethod(idx int, lv, rv reflect.Value) reflect.Value {
+	return lv.Method(idx).Call([]reflect.Value{rv})[0]
+}
+
+// lteq compares lv and rv with the ordering methods. This must only be called
+// if orders returns true.
+func (m *methods) lteq(lv, rv reflect.Value) (lt, eq bool) {
+	if m.compare >= 0 {
+		c := callMethod(m.compare, lv, rv).Int()
+		return c < 0, c == 0
+	}
+	if callMethod(m.less, lv, rv).Bool() {
+		return true, false
+	}
+	return false, !callMethod(m.less, rv, lv).Bool()
+}
+
+// name returns the name of the method that orders values.
+func (m *methods) name() string {
+	if m.compare >= 0 {
+		return "Compare"
+	}
+	return "Less"
+}
+
+// callable returns whether the methods can be called on lv and rv: methods
+// are never called on nil pointers or nil interfaces, which instead follow
+// the normal nil ordering.
+func callable(lv, rv reflect.Value) bool {
+	switch lv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return !lv.IsNil() && !rv.IsNil()
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+type tcompare struct {
+	v int
+}
+
+func (t tcompare) Compare(o tcompare) int { return t.v - o.v }
+
+type tequal struct {
+	A int
+	b int
+}
+
+// Equal ignores A entirely.
+func (t tequal) Equal(o tequal) bool { return t.b == o.b }
+
+type tlessptr struct {
+	v int
+}
+
+func (t *tlessptr) Less(o *tlessptr) bool { return t.v < o.v }
+
+func TestMethods(t *testing.T) {
+	type nested struct {
+		C tcompare
+		L tless
+	}
+	for _, test := range []struct {
+		l     any
+		r     any
+		less  bool
+		equal bool
+	}{
+		{tless{1}, tless{2}, true, false},
+		{tless{2}, tless{2}, false, true},
+		{tless{3}, tless{2}, false, false},
+
+		{tcompare{1}, tcompare{2}, true, false},
+		{tcompare{2}, tcompare{2}, false, true},
+
+		{tequal{1, 1}, tequal{2, 1}, false, true},
+		{tequal{1, 1}, tequal{2, 2}, true, false},
+		{tequal{3, 1}, tequal{2, 2}, false, false},
+
+		{&tlessptr{1}, &tlessptr{2}, true, false},
+		{(*tlessptr)(nil), &tlessptr{2}, true, false},
+		{(*tlessptr)(nil), (*tlessptr)(nil), false, true},
+
+		{nested{tcompare{1}, tless{9}}, nested{tcompare{2}, tless{0}}, true, false},
+		{nested{tcompare{1}, tless{9}}, nested{tcompare{1}, tless{0}}, false, false},
+		{[]tless{{1}, {2}}, []tless{{1}, {3}}, true, false},
+
+		//
+	} {
+		lt, eq := Less(test.l, test.r), Equal(test.l, test.r)
+		if lt != test.less {
+			t.Errorf("l %v r %v, got less? %v, exp less? %v", test.l, test.r, lt, test.less)
+		}
+		if eq != test.equal {
+			t.Errorf("l %v r %v, got equal? %v, exp equal? %v", test.l, test.r, eq, test.equal)
+		}
+	}
+}
+
+func TestMethodsSort(t *testing.T) {
+	for _, test := range []struct {
+		in  any
+		exp any
+	}{
+		{
+			[]tcompare{{3}, {1}, {2}},
+			[]tcompare{{1}, {2}, {3}},
+		},
+		{
+			[]*tlessptr{{3}, nil, {1}},
+			[]*tlessptr{nil, {1}, {3}},
+		},
+		{
+			[]struct{ L tless }{{tless{2}}, {tless{1}}},
+			[]struct{ L tless }{{tless{1}}, {tless{2}}},
+		},
+
+		//
+	} {
+		Sort(test.in)
+		if !reflect.DeepEqual(test.in, test.exp) {
+			t.Errorf("got %v != exp %v", test.in, test.exp)
+		}
+	}
+
+	d := Diff(struct{ C tcompare }{tcompare{1}}, struct{ C tcompare }{tcompare{2}})
+	if !reflect.DeepEqual(d, []Difference{{".C", tcompare{1}, tcompare{2}}}) {
+		t.Errorf("got unexpected diff %v", d)
+	}
+}
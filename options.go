@@ -0,0 +1,371 @@
+package types
+
+import (
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Option modifies how values are compared and sorted. Options are passed to
+// the *With variants of the functions in this package, such as CompareWith
+// and SortWith, as well as to CompareFunc, EqualFunc, and the Try functions.
+type Option func(*options)
+
+type options struct {
+	ignoreTypes    map[reflect.Type]struct{}
+	unorderedTypes map[reflect.Type]struct{}
+	nilMode        NilMode
+
+	lexicographic bool
+	stable        bool
+	descending    bool
+	descPaths     [][]string
+	nils          nilPlacement
+
+	cloneUnexported bool
+
+	comparers map[reflect.Type]comparer
+
+	ignorePaths  [][]string
+	ignoreFields []func(Path, reflect.StructField) bool
+
+	// paths is whether any option needs the path of values being
+	// compared or sorted, which is otherwise not tracked.
+	paths bool
+
+	tolerant bool // whether any float tolerance is set
+	floatAbs float64
+	floatRel float64
+	floatULP uint64
+}
+
+// IgnoreTypes ignores values of the same types as the input values. Ignored
+// values always compare as equal, and Sort does not sort within them.
+//
+// For example, IgnoreTypes(time.Time{}) ignores every time.Time field.
+func IgnoreTypes(vals ...any) Option {
+	return func(o *options) {
+		if o.ignoreTypes == nil {
+			o.ignoreTypes = make(map[reflect.Type]struct{})
+		}
+		for _, v := range vals {
+			o.ignoreTypes[reflect.TypeOf(v)] = struct{}{}
+		}
+	}
+}
+
+// FloatAbsTolerance considers two floats equal if the absolute difference
+// between them is at most eps. This applies to float fields and to both the
+// real and imaginary parts of complex values.
+//
+// If multiple float tolerances are used, floats are equal if they are within
+// any of the tolerances. Infinities and NaNs are only equal to themselves.
+// Note that approximate equality is not transitive: a may equal b and b may
+// equal c while a does not equal c.
+func FloatAbsTolerance(eps float64) Option {
+	return func(o *options) { o.tolerant, o.floatAbs = true, eps }
+}
+
+// FloatRelTolerance considers two floats equal if the absolute difference
+// between them is at most rel times the larger of their magnitudes. See
+// FloatAbsTolerance for more details.
+func FloatRelTolerance(rel float64) Option {
+	return func(o *options) { o.tolerant, o.floatRel = true, rel }
+}
+
+// FloatULPTolerance considers two floats equal if there are at most ulps
+// representable floats between them. The distance is measured at the width
+// of the value, so float32 values are measured in float32 ULPs. See
+// FloatAbsTolerance for more details.
+func FloatULPTolerance(ulps uint64) Option {
+	return func(o *options) { o.tolerant, o.floatULP = true, ulps }
+}
+
+func (o *options) withinTolerance(l, r float64, bits int) bool {
+	if math.IsNaN(l) || math.IsNaN(r) || math.IsInf(l, 0) || math.IsInf(r, 0) {
+		return false
+	}
+	diff := math.Abs(l - r)
+	if diff <= o.floatAbs {
+		return true
+	}
+	if diff <= o.floatRel*max(math.Abs(l), math.Abs(r)) {
+		return true
+	}
+	return o.floatULP > 0 && ulpDistance(l, r, bits) <= o.floatULP
+}
+
+// ulpDistance returns the number of representable floats between l and r at
+// the given bit width.
+func ulpDistance(l, r float64, bits int) uint64 {
+	var li, ri int64
+	if bits == 32 {
+		li, ri = int64(ordered32(float32(l))), int64(ordered32(float32(r)))
+	} else {
+		li, ri = ordered64(l), ordered64(r)
+	}
+	if li > ri {
+		li, ri = ri, li
+	}
+	return uint64(ri) - uint64(li)
+}
+
+// ordered64 maps the bits of a float to an integer such that the integers
+// order the same as the floats, with -0 and 0 mapping to the same integer.
+func ordered64(f float64) int64 {
+	i := int64(math.Float64bits(f))
+	if i < 0 {
+		i = math.MinInt64 - i
+	}
+	return i
+}
+
+func ordered32(f float32) int32 {
+	i := int32(math.Float32bits(f))
+	if i < 0 {
+		i = math.MinInt32 - i
+	}
+	return i
+}
+
+// NilMode controls how nil slices and maps compare to empty, non-nil slices
+// and maps.
+type NilMode uint8
+
+const (
+	// NilEqualsEmpty treats nil and empty as equal. This is the default.
+	NilEqualsEmpty NilMode = iota
+
+	// NilBeforeEmpty treats nil and empty as distinct, with nil ordered
+	// before empty.
+	NilBeforeEmpty
+
+	// NilNormalize treats nil and empty as equal, and additionally
+	// normalizes empty slices and maps to nil when sorting: SortWith and
+	// DistinctInPlaceWith set every empty slice and map that they can set
+	// to nil, including map values.
+	NilNormalize
+)
+
+// NilEmpty sets how nil slices and maps compare to empty slices and maps. This
+// applies to comparing, hashing, sorting and DistinctInPlaceWith alike.
+func NilEmpty(mode NilMode) Option {
+	return func(o *options) { o.nilMode = mode }
+}
+
+// lteqEmpty compares two slices or maps that are both empty.
+func (o *options) lteqEmpty(lv, rv reflect.Value) (lt, eq bool) {
+	if o.nilMode != NilBeforeEmpty || lv.Kind() == reflect.Array {
+		return false, true
+	}
+	ln, rn := lv.IsNil(), rv.IsNil()
+	return ln && !rn, ln == rn
+}
+
+// normalize returns whether v is an empty, non-nil slice or map that should be
+// set to nil.
+func (o *options) normalize(v reflect.Value) bool {
+	return o.normalizeEntry(v) && v.CanSet()
+}
+
+// normalizeEntry is like normalize, but for map values, which are never
+// settable themselves and must be replaced in the map.
+func (o *options) normalizeEntry(v reflect.Value) bool {
+	if o.nilMode != NilNormalize {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0 && !v.IsNil()
+	}
+	return false
+}
+
+// Lexicographic compares slices and maps element by element, using length
+// only as a tie-breaker, the same way strings compare. By default, shorter
+// slices and maps are always less, so that []string{"b"} is less than
+// []string{"a", "a"}; with this option, it is greater.
+//
+// Maps compare their sorted keys element by element first, then their
+// lengths, and then their values.
+func Lexicographic() Option {
+	return func(o *options) { o.lexicographic = true }
+}
+
+// Stable sorts slices stably: elements that compare equal keep their original
+// order. By default, Sort and DistinctInPlace do not guarantee the order of
+// elements that compare equal, such as structs that only differ in their
+// unexported fields. DistinctInPlace keeps the first of equal elements.
+func Stable() Option {
+	return func(o *options) { o.stable = true }
+}
+
+// Descending reverses the order of values, so that Sort sorts descending. With
+// no paths, every comparison is reversed: Less, Compare, and CompareFunc
+// return the opposite order, and Sort sorts every slice descending.
+//
+// With paths, only the order of values at the given paths is reversed. Paths
+// are written as described on IgnorePaths. While sorting, the elements of a
+// slice are compared against each other at the index [*], so that, for
+// example:
+//
+//	Descending("[*]")               // sorts a top level slice descending
+//	Descending("Items[*].Score")    // sorts Items by descending Score
+//
+// Reversing a value that is already reversed, such as a field tagged desc,
+// restores its order. This does not affect where NilsFirst and NilsLast place
+// nils. This panics if a path is invalid.
+func Descending(paths ...string) Option {
+	if len(paths) == 0 {
+		return func(o *options) { o.descending = true }
+	}
+	patterns := mustParsePatterns(paths)
+	return func(o *options) {
+		o.descPaths = append(o.descPaths, patterns...)
+		o.paths = true
+	}
+}
+
+func (o *options) descAt(path []string) bool {
+	for _, pattern := range o.descPaths {
+		if matchPattern(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+type nilPlacement uint8
+
+const (
+	nilsNatural nilPlacement = iota
+	nilsFirst
+	nilsLast
+)
+
+// NilsFirst orders nil pointers, nil interfaces, NaNs, and empty slices and
+// maps before all other values, even when reversed with Descending or a desc
+// tag. Without NilsFirst or NilsLast, these are first when ascending and last
+// when descending.
+func NilsFirst() Option {
+	return func(o *options) { o.nils = nilsFirst }
+}
+
+// NilsLast orders nil pointers, nil interfaces, NaNs, and empty slices and
+// maps after all other values, even when reversed with Descending or a desc
+// tag. Nil slices and maps are after empty ones if NilBeforeEmpty is used.
+func NilsLast() Option {
+	return func(o *options) { o.nils = nilsLast }
+}
+
+// natural returns whether values are ordered in the default order, which
+// allows sorting primitives without comparing through reflection.
+func (o *options) natural() bool {
+	return !o.descending && o.descPaths == nil && o.nils == nilsNatural
+}
+
+// noOptions is shared by every call without options, and must not be
+// modified.
+var noOptions options
+
+func newOptions(opts []Option) *options {
+	if len(opts) == 0 {
+		return &noOptions
+	}
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) ignored(t reflect.Type) bool {
+	if o.ignoreTypes == nil {
+		return false
+	}
+	_, ignored := o.ignoreTypes[t]
+	return ignored
+}
+
+// IgnorePaths ignores values at the given paths. Ignored values always compare
+// as equal, and Sort does not sort within them.
+//
+// Paths are written as described on Path, optionally without the leading dot,
+// and a segment can be * to match any struct field (.*) or any slice index or
+// map key ([*]). For example:
+//
+//	IgnorePaths("Metadata.ResourceVersion", "Items[*].Status.LastSeen")
+//
+// A path ending in a map key ignores that key's value, but the key itself must
+// still exist in both maps. While sorting, the elements of a slice are
+// compared against each other at the index [*]. This panics if a path is
+// invalid.
+func IgnorePaths(paths ...string) Option {
+	patterns := mustParsePatterns(paths)
+	return func(o *options) {
+		o.ignorePaths = append(o.ignorePaths, patterns...)
+		o.paths = true
+	}
+}
+
+// IgnoreFields ignores struct fields for which ignore returns true. The
+// function is called with the path of the field (including the field itself)
+// and the field. Ignored fields always compare as equal, and Sort does not
+// sort within them.
+func IgnoreFields(ignore func(path Path, sf reflect.StructField) bool) Option {
+	return func(o *options) {
+		o.ignoreFields = append(o.ignoreFields, ignore)
+		o.paths = true
+	}
+}
+
+func (o *options) ignoredPath(path []string) bool {
+	for _, pattern := range o.ignorePaths {
+		if matchPattern(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *options) ignoredField(path []string, sf reflect.StructField) bool {
+	if o.ignoredPath(path) {
+		return true
+	}
+	if len(o.ignoreFields) == 0 {
+		return false
+	}
+	p := Path(strings.Join(path, ""))
+	for _, ignore := range o.ignoreFields {
+		if ignore(p, sf) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnorderedTypes compares slices and arrays of the same types as the input
+// values as multisets: the order of elements does not matter, but the number
+// of times each element occurs does. This is the option form of the set
+// struct tag, for types that cannot be tagged.
+//
+// For example, UnorderedTypes([]string(nil)) compares every []string ignoring
+// order.
+func UnorderedTypes(vals ...any) Option {
+	return func(o *options) {
+		if o.unorderedTypes == nil {
+			o.unorderedTypes = make(map[reflect.Type]struct{})
+		}
+		for _, v := range vals {
+			o.unorderedTypes[reflect.TypeOf(v)] = struct{}{}
+		}
+	}
+}
+
+func (o *options) unordered(t reflect.Type) bool {
+	if o.unorderedTypes == nil {
+		return false
+	}
+	_, unordered := o.unorderedTypes[t]
+	return unordered
+}
@@ -0,0 +1,464 @@
+package types
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type ignoredID string
+
+func TestIgnoreTypes(t *testing.T) {
+	type item struct {
+		ID  ignoredID
+		Val int
+		Tag []ignoredID
+	}
+
+	l := item{"a", 1, []ignoredID{"z", "y"}}
+	r := item{"b", 1, []ignoredID{"x"}}
+
+	if Equal(l, r) {
+		t.Errorf("got equal without options, exp unequal")
+	}
+	if !EqualWith(l, r, IgnoreTypes(ignoredID(""), []ignoredID(nil))) {
+		t.Errorf("got unequal with ignored types, exp equal")
+	}
+	if c := CompareWith(item{"b", 1, nil}, item{"a", 2, nil}, IgnoreTypes(ignoredID(""))); c != -1 {
+		t.Errorf("got compare %d != exp -1", c)
+	}
+	if LessWith(l, r, IgnoreTypes(ignoredID(""), []ignoredID(nil))) {
+		t.Errorf("got less with ignored types, exp not less")
+	}
+	if d := DiffWith(l, r, IgnoreTypes(ignoredID(""))); !reflect.DeepEqual(d, []Difference{
+		{".Tag[1]", ignoredID("y"), nil},
+	}) {
+		t.Errorf("got unexpected diff %v", d)
+	}
+
+	s := []item{{"c", 2, []ignoredID{"b", "a"}}, {"b", 1, []ignoredID{"d", "c"}}}
+	SortWith(s, IgnoreTypes([]ignoredID(nil)))
+	exp := []item{{"b", 1, []ignoredID{"d", "c"}}, {"c", 2, []ignoredID{"b", "a"}}}
+	if !reflect.DeepEqual(s, exp) {
+		t.Errorf("got %v != exp %v", s, exp)
+	}
+}
+
+func TestFloatTolerance(t *testing.T) {
+	tenth := 0.1 // not a constant, so that tenth + 0.2 != 0.3
+	type metrics struct {
+		Mean float64
+		P99  float32
+		Z    complex128
+	}
+	for _, test := range []struct {
+		l     any
+		r     any
+		opts  []Option
+		equal bool
+	}{
+		{tenth + 0.2, 0.3, nil, false},
+		{tenth + 0.2, 0.3, []Option{FloatAbsTolerance(1e-9)}, true},
+		{1.0, 1.1, []Option{FloatAbsTolerance(1e-9)}, false},
+		{1000.0, 1001.0, []Option{FloatRelTolerance(0.01)}, true},
+		{1.0, 1.1, []Option{FloatRelTolerance(0.01)}, false},
+		{1.0, math.Nextafter(math.Nextafter(1, 2), 2), []Option{FloatULPTolerance(2)}, true},
+		{1.0, math.Nextafter(math.Nextafter(1, 2), 2), []Option{FloatULPTolerance(1)}, false},
+		{math.Copysign(0, -1), math.SmallestNonzeroFloat64, []Option{FloatULPTolerance(1)}, true},
+		{float32(1), math.Nextafter32(1, 2), []Option{FloatULPTolerance(1)}, true},
+		{math.Inf(1), math.MaxFloat64, []Option{FloatRelTolerance(1)}, false},
+		{math.NaN(), 1.0, []Option{FloatAbsTolerance(math.Inf(1))}, false},
+		{math.NaN(), math.NaN(), []Option{FloatAbsTolerance(1)}, true},
+
+		// Either tolerance may match.
+		{1.0, 1.5, []Option{FloatAbsTolerance(1), FloatRelTolerance(0)}, true},
+
+		{
+			metrics{tenth + 0.2, 0.3, complex(tenth+0.2, 1)},
+			metrics{0.3, float32(tenth) + 0.2, complex(0.3, 1)},
+			[]Option{FloatAbsTolerance(1e-6)},
+			true,
+		},
+		{
+			metrics{Z: complex(1, tenth+0.2)},
+			metrics{Z: complex(1, 0.4)},
+			[]Option{FloatAbsTolerance(1e-6)},
+			false,
+		},
+
+		//
+	} {
+		if eq := EqualWith(test.l, test.r, test.opts...); eq != test.equal {
+			t.Errorf("l %v r %v, got equal? %v, exp equal? %v", test.l, test.r, eq, test.equal)
+		}
+		if c := CompareWith(test.l, test.r, test.opts...); (c == 0) != test.equal {
+			t.Errorf("l %v r %v, got compare %d, exp equal? %v", test.l, test.r, c, test.equal)
+		}
+	}
+
+	// Values outside of the tolerance still order normally.
+	if !LessWith(1.0, 2.0, FloatAbsTolerance(0.5)) || LessWith(2.0, 1.0, FloatAbsTolerance(0.5)) {
+		t.Errorf("got wrong ordering outside of tolerance")
+	}
+}
+
+func TestNilEmpty(t *testing.T) {
+	type holder struct {
+		S []int
+		M map[string]int
+	}
+	for _, test := range []struct {
+		l     any
+		r     any
+		mode  NilMode
+		less  bool
+		equal bool
+	}{
+		{[]int(nil), []int{}, NilEqualsEmpty, false, true},
+		{[]int(nil), []int{}, NilNormalize, false, true},
+		{[]int(nil), []int{}, NilBeforeEmpty, true, false},
+		{[]int{}, []int(nil), NilBeforeEmpty, false, false},
+		{[]int(nil), []int(nil), NilBeforeEmpty, false, true},
+		{[]int{}, []int{}, NilBeforeEmpty, false, true},
+		{[0]int{}, [0]int{}, NilBeforeEmpty, false, true},
+
+		{map[int]int(nil), map[int]int{}, NilEqualsEmpty, false, true},
+		{map[int]int(nil), map[int]int{}, NilBeforeEmpty, true, false},
+
+		{holder{M: map[string]int{}}, holder{}, NilBeforeEmpty, false, false},
+		{holder{S: []int{}}, holder{}, NilEqualsEmpty, false, true},
+
+		//
+	} {
+		opt := NilEmpty(test.mode)
+		lt, eq := LessWith(test.l, test.r, opt), EqualWith(test.l, test.r, opt)
+		if lt != test.less {
+			t.Errorf("l %#v r %#v mode %d, got less? %v, exp less? %v", test.l, test.r, test.mode, lt, test.less)
+		}
+		if eq != test.equal {
+			t.Errorf("l %#v r %#v mode %d, got equal? %v, exp equal? %v", test.l, test.r, test.mode, eq, test.equal)
+		}
+		lh, rh := HashWith(test.l, opt), HashWith(test.r, opt)
+		if eq != (lh == rh) {
+			t.Errorf("l %#v r %#v mode %d, got equal? %v but hashes %x, %x", test.l, test.r, test.mode, eq, lh, rh)
+		}
+		if d := DiffWith(test.l, test.r, opt); eq != (d == nil) {
+			t.Errorf("l %#v r %#v mode %d, got equal? %v but diff %v", test.l, test.r, test.mode, eq, d)
+		}
+	}
+}
+
+func TestNilNormalize(t *testing.T) {
+	type holder struct {
+		S []int
+		M map[string][]int
+	}
+	in := []holder{
+		{S: []int{}, M: map[string][]int{"a": {}, "b": {2, 1}}},
+		{S: nil, M: map[string][]int{}},
+	}
+	DistinctInPlaceWith(&in, NilEmpty(NilNormalize))
+	exp := []holder{
+		{S: nil, M: nil},
+		{S: nil, M: map[string][]int{"a": nil, "b": {1, 2}}},
+	}
+	if !reflect.DeepEqual(in, exp) {
+		t.Errorf("got %#v != exp %#v", in, exp)
+	}
+
+	empty := []int{}
+	DistinctInPlaceWith(&empty, NilEmpty(NilNormalize))
+	if empty != nil {
+		t.Errorf("got %#v, exp nil", empty)
+	}
+
+	// A NaN key is never found, so normalizing its value must not add
+	// an entry.
+	nan := map[float64][]int{math.NaN(): {}}
+	SortWith(&nan, NilEmpty(NilNormalize))
+	if len(nan) != 1 {
+		t.Errorf("got %v, exp one entry", nan)
+	}
+
+	dups := []holder{{S: []int{}}, {}}
+	DistinctInPlace(&dups)
+	if len(dups) != 1 {
+		t.Errorf("got %#v, exp one element", dups)
+	}
+}
+
+func TestHashWithTolerance(t *testing.T) {
+	opt := FloatAbsTolerance(0.5)
+	l, r := struct{ F float64 }{1}, struct{ F float64 }{1.25}
+	if !EqualWith(l, r, opt) || HashWith(l, opt) != HashWith(r, opt) {
+		t.Errorf("got approximately equal floats with different hashes")
+	}
+}
+
+func TestLexicographic(t *testing.T) {
+	for _, test := range []struct {
+		l     any
+		r     any
+		less  bool
+		equal bool
+	}{
+		{[]string{"b"}, []string{"a", "a"}, false, false},
+		{[]string{"a"}, []string{"a", "a"}, true, false},
+		{[]int{1, 10}, []int{2}, true, false},
+		{[]int{1, 10}, []int{1, 10}, false, true},
+		{[]int{}, []int{0}, true, false},
+
+		{map[int]int{1: 9, 5: 0}, map[int]int{2: 0}, true, false},
+		{map[int]int{1: 0}, map[int]int{1: 0, 2: 0}, true, false},
+		{map[int]int{1: 9}, map[int]int{1: 0, 2: 0}, true, false},
+		{map[int]int{1: 1}, map[int]int{1: 0}, false, false},
+
+		{tagged{Labels: []string{"b"}}, tagged{Labels: []string{"a", "c"}}, false, false},
+
+		//
+	} {
+		lt, eq := LessWith(test.l, test.r, Lexicographic()), EqualWith(test.l, test.r, Lexicographic())
+		if lt != test.less {
+			t.Errorf("l %v r %v, got less? %v, exp less? %v", test.l, test.r, lt, test.less)
+		}
+		if eq != test.equal {
+			t.Errorf("l %v r %v, got equal? %v, exp equal? %v", test.l, test.r, eq, test.equal)
+		}
+	}
+
+	versions := [][]int{{2}, {1, 10}, {1, 2, 3}, {1, 2}}
+	SortWith(versions, Lexicographic())
+	if exp := [][]int{{1, 2}, {1, 2, 3}, {1, 10}, {2}}; !reflect.DeepEqual(versions, exp) {
+		t.Errorf("got %v != exp %v", versions, exp)
+	}
+}
+
+func TestElementsMatch(t *testing.T) {
+	type pod struct {
+		Name  string
+		Ports []int
+	}
+	f, g := func() {}, func() {}
+	c1, c2 := make(chan int), make(chan int)
+	for _, test := range []struct {
+		l     any
+		r     any
+		match bool
+	}{
+		{[]int{1, 2, 3}, []int{3, 1, 2}, true},
+		{[]int{1, 1, 2}, []int{1, 2, 2}, false},
+		{[]int{1, 2}, []int{1, 2, 2}, false},
+		{[]int(nil), []int{}, true},
+		{[2]string{"a", "b"}, [2]string{"b", "a"}, true},
+		{
+			[]pod{{"a", []int{1, 2}}, {"b", nil}},
+			[]pod{{"b", nil}, {"a", []int{1, 2}}},
+			true,
+		},
+		{
+			[]pod{{"a", []int{1, 2}}, {"b", nil}},
+			[]pod{{"b", nil}, {"a", []int{2, 1}}},
+			false,
+		},
+
+		// Elements that are not totally ordered are matched pairwise.
+		{[]func(){f, g}, []func(){g, f}, true},
+		{[]func(){f, f}, []func(){g, f}, false},
+		{[]chan int{c1, c2}, []chan int{c2, c1}, true},
+		{[]any{f, 1, g}, []any{g, 1, f}, true},
+		{[]tequal{{1, 1}, {2, 2}}, []tequal{{2, 1}, {1, 2}}, true},
+		{[]tequal{{1, 1}, {2, 2}}, []tequal{{2, 1}, {1, 1}}, false},
+
+		//
+	} {
+		if got := ElementsMatch(test.l, test.r); got != test.match {
+			t.Errorf("l %v r %v, got match? %v, exp match? %v", test.l, test.r, got, test.match)
+		}
+	}
+
+	// Matching pairwise discards the explanation of the sorted mismatch.
+	opt := UnorderedTypes([]func(){})
+	if c, ex := CompareExplainWith([]func(){f, g}, []func(){g, f}, opt); c != 0 || ex != (Explanation{}) {
+		t.Errorf("got compare %d, %v, exp equal", c, ex)
+	}
+}
+
+func TestUnorderedTypes(t *testing.T) {
+	type pod struct {
+		Name  string
+		Ports []int
+	}
+	l := []pod{{"a", []int{1, 2}}, {"b", []int{3}}}
+	r := []pod{{"b", []int{3}}, {"a", []int{2, 1}}}
+	opt := UnorderedTypes([]pod(nil), []int(nil))
+
+	if Equal(l, r) {
+		t.Errorf("got equal without options, exp unequal")
+	}
+	if !EqualWith(l, r, opt) || CompareWith(l, r, opt) != 0 {
+		t.Errorf("got unequal with unordered types, exp equal")
+	}
+	if HashWith(l, opt) != HashWith(r, opt) {
+		t.Errorf("got different hashes for equal unordered values")
+	}
+	if EqualWith([]int{1, 1}, []int{1, 2}, opt) {
+		t.Errorf("got equal, exp duplicates to matter")
+	}
+	if d := DiffWith(pod{"a", []int{1}}, pod{"a", []int{2}}, opt); !reflect.DeepEqual(d, []Difference{{".Ports", []int{1}, []int{2}}}) {
+		t.Errorf("got unexpected diff %v", d)
+	}
+}
+
+type stableLess struct {
+	k   int
+	tag int
+}
+
+func (s stableLess) Less(o stableLess) bool { return s.k < o.k }
+
+func TestStable(t *testing.T) {
+	type rec struct {
+		K   int
+		tag int
+	}
+	const n = 200
+
+	recs := make([]rec, n)
+	lesses := make([]stableLess, n)
+	ifaces := make([]any, n)
+	floats := make([]float64, n)
+	for i := range n {
+		recs[i] = rec{n - i%3, i}
+		lesses[i] = stableLess{n - i%3, i}
+		ifaces[i] = rec{n - i%3, i}
+		floats[i] = 0
+		if i%2 == 0 {
+			floats[i] = math.Copysign(0, -1)
+		}
+	}
+	exp := slices.Clone(floats)
+
+	SortStable(recs)
+	SortStable(lesses)
+	SortWith(ifaces, Stable())
+	SortStable(floats)
+
+	for i := 1; i < n; i++ {
+		if l, r := recs[i-1], recs[i]; l.K == r.K && l.tag > r.tag {
+			t.Fatalf("struct: got %v before %v, exp stable", l, r)
+		}
+		if l, r := lesses[i-1], lesses[i]; l.k == r.k && l.tag > r.tag {
+			t.Fatalf("Less: got %v before %v, exp stable", l, r)
+		}
+		if l, r := ifaces[i-1].(rec), ifaces[i].(rec); l.K == r.K && l.tag > r.tag {
+			t.Fatalf("interface: got %v before %v, exp stable", l, r)
+		}
+		if math.Signbit(floats[i]) != math.Signbit(exp[i]) {
+			t.Fatalf("float: got sign change at %d, exp stable", i)
+		}
+	}
+
+	distinct := []rec{{2, 0}, {1, 1}, {2, 2}, {1, 3}}
+	DistinctInPlaceWith(&distinct, Stable())
+	if exp := []rec{{1, 1}, {2, 0}}; !reflect.DeepEqual(distinct, exp) {
+		t.Errorf("got %v != exp %v", distinct, exp)
+	}
+}
+
+func TestDescending(t *testing.T) {
+	type item struct {
+		Score float64
+		Name  string
+	}
+
+	ints := []int{2, 3, 1}
+	SortWith(ints, Descending())
+	if exp := []int{3, 2, 1}; !reflect.DeepEqual(ints, exp) {
+		t.Errorf("got %v != exp %v", ints, exp)
+	}
+	if c := CompareWith(1, 2, Descending()); c != 1 {
+		t.Errorf("got compare %d != exp 1", c)
+	}
+	if c := CompareFunc[int](Descending())(1, 2); c != 1 {
+		t.Errorf("got compare func %d != exp 1", c)
+	}
+
+	// Nested slices are each sorted descending, and each comparison is
+	// only reversed once.
+	nested := [][]int{{1, 2}, {3, 1}}
+	SortWith(nested, Descending())
+	if exp := [][]int{{3, 1}, {2, 1}}; !reflect.DeepEqual(nested, exp) {
+		t.Errorf("got %v != exp %v", nested, exp)
+	}
+
+	report := struct {
+		Items []item
+		Names []string
+	}{
+		[]item{{1, "a"}, {3, "b"}, {2, "c"}},
+		[]string{"b", "a", "c"},
+	}
+	SortWith(&report, Descending("Items[*].Score"))
+	if exp := []item{{3, "b"}, {2, "c"}, {1, "a"}}; !reflect.DeepEqual(report.Items, exp) {
+		t.Errorf("got %v != exp %v", report.Items, exp)
+	}
+	if exp := []string{"a", "b", "c"}; !reflect.DeepEqual(report.Names, exp) {
+		t.Errorf("got %v != exp %v", report.Names, exp)
+	}
+
+	strs := []string{"b", "a", "c"}
+	SortWith(strs, Descending("[*]"))
+	if exp := []string{"c", "b", "a"}; !reflect.DeepEqual(strs, exp) {
+		t.Errorf("got %v != exp %v", strs, exp)
+	}
+
+	// Reversing a desc tagged field restores its order.
+	if c := CompareWith(tagged{Age: 1}, tagged{Age: 2}, Descending("Age")); c != -1 {
+		t.Errorf("got compare %d != exp -1", c)
+	}
+}
+
+func TestNilsFirstLast(t *testing.T) {
+	one, two := 1, 2
+	nan := math.NaN()
+
+	for _, test := range []struct {
+		opts []Option
+		exp  []*int
+	}{
+		{nil, []*int{nil, &one, &two}},
+		{[]Option{Descending()}, []*int{&two, &one, nil}},
+		{[]Option{NilsLast()}, []*int{&one, &two, nil}},
+		{[]Option{NilsLast(), Descending()}, []*int{&two, &one, nil}},
+		{[]Option{NilsFirst(), Descending()}, []*int{nil, &two, &one}},
+	} {
+		ptrs := []*int{&two, nil, &one}
+		SortWith(ptrs, test.opts...)
+		if !reflect.DeepEqual(ptrs, test.exp) {
+			t.Errorf("opts %d: got %v != exp %v", len(test.opts), ptrs, test.exp)
+		}
+	}
+
+	floats := []float64{2, nan, 1}
+	SortWith(floats, NilsLast(), Descending())
+	if floats[0] != 2 || floats[1] != 1 || !math.IsNaN(floats[2]) {
+		t.Errorf("got %v, exp [2 1 NaN]", floats)
+	}
+
+	empties := [][]int{{}, {2}, nil, {1}}
+	SortWith(empties, NilsLast(), NilEmpty(NilBeforeEmpty))
+	if exp := [][]int{{1}, {2}, {}, nil}; !reflect.DeepEqual(empties, exp) {
+		t.Errorf("got %v != exp %v", empties, exp)
+	}
+
+	// Nils stay last under a desc tag.
+	type row struct {
+		P *int `types:"desc"`
+	}
+	rows := []row{{&one}, {nil}, {&two}}
+	SortWith(rows, NilsLast())
+	if exp := []row{{&two}, {&one}, {nil}}; !reflect.DeepEqual(rows, exp) {
+		t.Errorf("got %v != exp %v", rows, exp)
+	}
+}
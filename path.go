@@ -0,0 +1,149 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Path is a location within a value, such as .Spec.Replicas[3].Name. Struct
+// fields are written as .Field, slice and array elements as [i], and map
+// entries as [key] with the key formatted with %#v. The empty path is the
+// value itself. Pointers and interfaces do not add to the path.
+type Path string
+
+// A path is tracked as a stack of segments while walking values, each of
+// which is one of the forms below. Paths are only tracked if something needs
+// them, since formatting map keys is not free.
+
+func fieldSeg(name string) string { return "." + name }
+func indexSeg(i int) string       { return "[" + strconv.Itoa(i) + "]" }
+func keySeg(k reflect.Value) string {
+	return fmt.Sprintf("[%#v]", k.Interface())
+}
+
+// anySeg is the segment used for slice elements when their index is not
+// meaningful, such as when comparing elements while sorting or comparing
+// unordered slices.
+const anySeg = "[*]"
+
+func (s *state) push(seg string) { s.path = append(s.path, seg) }
+func (s *state) pop()            { s.path = s.path[:len(s.path)-1] }
+func (s *state) curPath() Path   { return Path(strings.Join(s.path, "")) }
+
+// lteqAt compares lv and rv with c at the path segment seg, treating the
+// values as equal if the path is ignored. This must only be called if paths
+// are being tracked.
+func (s *state) lteqAt(seg string, c *compiled, lv, rv reflect.Value) (lt, eq bool) {
+	s.push(seg)
+	defer s.pop()
+	if s.o.ignoredPath(s.path) {
+		return false, true
+	}
+	return s.lteqHere(c, lv, rv)
+}
+
+// lteqHere compares lv and rv with c at the current path, reversing the order
+// if the path is descending. This must only be called if paths are being
+// tracked.
+func (s *state) lteqHere(c *compiled, lv, rv reflect.Value) (lt, eq bool) {
+	if s.o.descPaths != nil && s.o.descAt(s.path) {
+		return s.reversed(c, lv, rv)
+	}
+	return c.lteq(s, lv, rv)
+}
+
+// mustParsePatterns parses each pattern, panicking if any is invalid.
+func mustParsePatterns(paths []string) [][]string {
+	patterns := make([][]string, 0, len(paths))
+	for _, p := range paths {
+		pattern, err := parsePattern(p)
+		if err != nil {
+			panic(err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// parsePattern parses a path pattern into segments. A pattern is a path, with
+// or without the leading dot, in which a segment can be * to match any field
+// (.*) or any index or key ([*]).
+func parsePattern(pattern string) ([]string, error) {
+	var segs []string
+	p := pattern
+	if p != "" && p[0] != '.' && p[0] != '[' {
+		p = "." + p
+	}
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			end := strings.IndexAny(p[1:], ".[") + 1
+			if end == 0 {
+				end = len(p)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("types: invalid path pattern %q: empty field name", pattern)
+			}
+			segs = append(segs, p[:end])
+			p = p[end:]
+		case '[':
+			end := closingBracket(p)
+			if end < 0 {
+				return nil, fmt.Errorf("types: invalid path pattern %q: unterminated [", pattern)
+			}
+			segs = append(segs, p[:end+1])
+			p = p[end+1:]
+		default:
+			return nil, fmt.Errorf("types: invalid path pattern %q: unexpected %q", pattern, p[0])
+		}
+	}
+	return segs, nil
+}
+
+// closingBracket returns the index of the ] that closes the [ that p starts
+// with, skipping over quoted strings, or -1.
+func closingBracket(p string) int {
+	var quote byte
+	for i := 1; i < len(p); i++ {
+		switch c := p[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// matchPattern returns whether the path segments match the pattern segments.
+func matchPattern(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i, pat := range pattern {
+		seg := path[i]
+		switch pat {
+		case ".*":
+			if seg[0] != '.' {
+				return false
+			}
+		case anySeg:
+			if seg[0] != '[' {
+				return false
+			}
+		default:
+			if pat != seg {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIgnorePaths(t *testing.T) {
+	type status struct {
+		LastSeen int
+		Ready    bool
+	}
+	type item struct {
+		Name   string
+		Status status
+	}
+	type object struct {
+		Metadata struct {
+			Name            string
+			ResourceVersion int
+		}
+		Items  []item
+		Labels map[string]int
+	}
+
+	var l, r object
+	l.Metadata.Name, r.Metadata.Name = "a", "a"
+	l.Metadata.ResourceVersion, r.Metadata.ResourceVersion = 1, 2
+	l.Items = []item{{"x", status{1, true}}, {"y", status{2, true}}}
+	r.Items = []item{{"x", status{3, true}}, {"y", status{4, true}}}
+	l.Labels = map[string]int{"gen": 1, "app": 1}
+	r.Labels = map[string]int{"gen": 2, "app": 1}
+
+	ignore := IgnorePaths("Metadata.ResourceVersion", "Items[*].Status.LastSeen", `.Labels["gen"]`)
+	if Equal(l, r) {
+		t.Errorf("got equal without options, exp unequal")
+	}
+	if !EqualWith(l, r, ignore) {
+		t.Errorf("got unequal with ignored paths, exp equal")
+	}
+	if d := DiffWith(l, r, ignore); d != nil {
+		t.Errorf("got unexpected diff %v", d)
+	}
+	if HashWith(l, ignore) != HashWith(r, ignore) {
+		t.Errorf("got different hashes with ignored paths, exp equal")
+	}
+
+	r.Items[1].Status.Ready = false
+	if EqualWith(l, r, ignore) {
+		t.Errorf("got equal with unignored difference, exp unequal")
+	}
+	if d := DiffWith(l, r, ignore); !reflect.DeepEqual(d, []Difference{
+		{".Items[1].Status.Ready", true, false},
+	}) {
+		t.Errorf("got unexpected diff %v", d)
+	}
+
+	// Wildcard fields.
+	if !EqualWith(l, r, IgnorePaths("Metadata.*", "Items[*].Status.*", "Labels")) {
+		t.Errorf("got unequal with wildcard paths, exp equal")
+	}
+
+	// Ignored values are not sorted within, and elements compared
+	// while sorting are at the path [*].
+	s := []item{{"b", status{1, false}}, {"a", status{2, false}}}
+	SortWith(s, IgnorePaths("[*].Name"))
+	if exp := []item{{"b", status{1, false}}, {"a", status{2, false}}}; !reflect.DeepEqual(s, exp) {
+		t.Errorf("got %v != exp %v", s, exp)
+	}
+	nested := struct {
+		A []int
+		B []int
+	}{[]int{3, 1, 2}, []int{3, 1, 2}}
+	SortWith(&nested, IgnorePaths("A"))
+	if !reflect.DeepEqual(nested.A, []int{3, 1, 2}) || !reflect.DeepEqual(nested.B, []int{1, 2, 3}) {
+		t.Errorf("got %v, exp only B sorted", nested)
+	}
+}
+
+func TestIgnoreFields(t *testing.T) {
+	type inner struct {
+		ID  string
+		Val int
+	}
+	type outer struct {
+		ID    string
+		Inner inner
+		Ptrs  []*inner
+	}
+
+	var paths []Path
+	ignore := IgnoreFields(func(path Path, sf reflect.StructField) bool {
+		paths = append(paths, path)
+		return sf.Name == "ID"
+	})
+
+	l := outer{"a", inner{"b", 1}, []*inner{{"c", 2}}}
+	r := outer{"x", inner{"y", 1}, []*inner{{"z", 2}}}
+	if !EqualWith(l, r, ignore) {
+		t.Errorf("got unequal with ignored fields, exp equal")
+	}
+	if exp := []Path{".ID", ".Inner", ".Inner.ID", ".Inner.Val", ".Ptrs", ".Ptrs[0].ID", ".Ptrs[0].Val"}; !reflect.DeepEqual(paths, exp) {
+		t.Errorf("got paths %v != exp %v", paths, exp)
+	}
+	if HashWith(l, ignore) != HashWith(r, ignore) {
+		t.Errorf("got different hashes with ignored fields, exp equal")
+	}
+	r.Inner.Val = 2
+	if d := DiffWith(l, r, ignore); !reflect.DeepEqual(d, []Difference{
+		{".Inner.Val", 1, 2},
+	}) {
+		t.Errorf("got unexpected diff %v", d)
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	for _, test := range []struct {
+		in  string
+		exp []string
+		err string
+	}{
+		{in: "", exp: nil},
+		{in: "A.B", exp: []string{".A", ".B"}},
+		{in: ".A[*].B", exp: []string{".A", "[*]", ".B"}},
+		{in: `[0]["a]b"].*`, exp: []string{"[0]", `["a]b"]`, ".*"}},
+		{in: "A..B", err: "empty field name"},
+		{in: "A[0", err: "unterminated ["},
+		{in: "A[0]B", err: "unexpected"},
+	} {
+		got, err := parsePattern(test.in)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("parse %q: got err %v, exp %q", test.in, err, test.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("parse %q: got %q, %v != exp %q", test.in, got, err, test.exp)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("got no panic on invalid pattern")
+		}
+	}()
+	IgnorePaths("A[")
+}
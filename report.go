@@ -0,0 +1,81 @@
+package types
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Report describes what SortReport did.
+type Report struct {
+	// Reordered is whether any slice or array was not already sorted.
+	Reordered bool
+
+	// Skipped lists each value that could not be sorted, in the order
+	// they were found.
+	Skipped []Skipped
+}
+
+// Skipped is a value that could not be sorted, and why.
+type Skipped struct {
+	Path   Path
+	Reason string
+}
+
+// String returns the skipped value formatted as "path: reason".
+func (s Skipped) String() string {
+	p := s.Path
+	if p == "" {
+		p = "(root)"
+	}
+	return string(p) + ": " + s.Reason
+}
+
+// SortReport is like Sort, but reports whether anything was reordered and
+// which values could not be sorted, and returns an error rather than
+// panicking if s cannot be sorted.
+//
+// Sort can only sort values that it can modify. Arrays that are not
+// addressable (such as an array, or a struct holding an array, passed to Sort
+// by value), and interfaces and map values holding arrays that cannot be
+// replaced with a sorted copy, are skipped. Values that are ignored with
+// options are not reported.
+func SortReport(s any) (Report, error) {
+	return SortReportWith(s)
+}
+
+// SortReportWith is like SortReport, but with options that modify how values
+// are compared and which values are sorted.
+func SortReportWith(s any, opts ...Option) (r Report, err error) {
+	// Reporting needs paths. The options may be shared, so we track
+	// paths in a copy.
+	o := *newOptions(opts)
+	o.paths = true
+	st := &state{o: &o, report: &r}
+
+	defer recoverError(&err)
+	v := reflect.ValueOf(s)
+	if !v.IsValid() {
+		panic(&UncomparableError{})
+	}
+	innerSort(st, v)
+	return r, nil
+}
+
+func (s *state) reporting() bool {
+	return s.report != nil
+}
+
+// skip reports that the value at the current path could not be sorted.
+func (s *state) skip(reason string) {
+	if s.report != nil {
+		s.report.Skipped = append(s.report.Skipped, Skipped{s.curPath(), reason})
+	}
+}
+
+// noteOrder notes if the slice v, which is about to be sorted with less, is
+// not already sorted.
+func (s *state) noteOrder(v reflect.Value, less func(i, j int) bool) {
+	if !s.report.Reordered && !sort.SliceIsSorted(v.Interface(), less) {
+		s.report.Reordered = true
+	}
+}
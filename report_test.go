@@ -0,0 +1,83 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSortReport(t *testing.T) {
+	type doc struct {
+		Names []string
+		Grid  [2]int
+		Any   any
+		Nums  []float64
+	}
+
+	// Passed by value, the array and the interface holding an array
+	// cannot be modified, but slices can.
+	d := doc{
+		Names: []string{"b", "a"},
+		Grid:  [2]int{2, 1},
+		Any:   [2]int{2, 1},
+	}
+	r, err := SortReport(d)
+	if err != nil {
+		t.Fatalf("got unexpected err %v", err)
+	}
+	exp := Report{
+		Reordered: true,
+		Skipped: []Skipped{
+			{".Grid", "array is not addressable"},
+			{".Any", "interface holding an array is not settable"},
+		},
+	}
+	if !reflect.DeepEqual(r, exp) {
+		t.Errorf("got report %v != exp %v", r, exp)
+	}
+	if !reflect.DeepEqual(d.Names, []string{"a", "b"}) || d.Grid != [2]int{2, 1} {
+		t.Errorf("got unexpected sort result %v", d)
+	}
+
+	// Through a pointer, everything is sorted and nothing is skipped.
+	d = doc{
+		Names: []string{"b", "a"},
+		Grid:  [2]int{2, 1},
+		Any:   [2]int{2, 1},
+	}
+	r, _ = SortReport(&d)
+	if !r.Reordered || r.Skipped != nil {
+		t.Errorf("got report %v, exp reordered without skips", r)
+	}
+	if d.Grid != [2]int{1, 2} || d.Any != [2]int{1, 2} {
+		t.Errorf("got unexpected sort result %v", d)
+	}
+
+	// Sorting again reorders nothing.
+	if r, _ = SortReport(&d); r.Reordered {
+		t.Errorf("got reordered sorting sorted values")
+	}
+	nested := [][]int{{1, 2}, {3, 4}}
+	if r, _ = SortReport(nested); r.Reordered {
+		t.Errorf("got reordered sorting sorted nested slices")
+	}
+	nested[1] = []int{4, 3}
+	if r, _ = SortReport(nested); !r.Reordered {
+		t.Errorf("got not reordered sorting an unsorted inner slice")
+	}
+
+	nan := map[float64][2]int{math.NaN(): {2, 1}}
+	if r, _ = SortReport(nan); !reflect.DeepEqual(r.Skipped, []Skipped{{"[NaN]", "map key cannot be found to write back"}}) || len(nan) != 1 {
+		t.Errorf("got report %v for map %v, exp the NaN key skipped", r, nan)
+	}
+
+	if s := (Skipped{"", "array is not addressable"}).String(); s != "(root): array is not addressable" {
+		t.Errorf("got string %q", s)
+	}
+
+	var uerr *UncomparableError
+	if _, err := SortReport(nil); !errors.As(err, &uerr) {
+		t.Errorf("got err %v, exp *UncomparableError", err)
+	}
+}
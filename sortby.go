@@ -0,0 +1,136 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SortBy stably sorts a slice of structs, or of pointers to structs, by the
+// given keys, as a table would be sorted by its columns. The slice can also be
+// a pointer to a slice or array.
+//
+// Each key is a path of exported field names separated by dots, optionally
+// prefixed with - to sort that key descending (or + for ascending), such as:
+//
+//	SortBy(users, "LastName", "-Age", "Address.City")
+//
+// Elements are compared by the first key, then by the next key if the first
+// is equal, and so on; elements equal by every key keep their original order.
+// Each key's values are compared with the rules of Less, but values are not
+// sorted within. If an element or a pointer along a key's path is nil, that
+// key is missing for the element and is ordered like a nil pointer.
+//
+// Keys are resolved against the element type once. This panics if a key does
+// not name an exported field, and with an *UncomparableError if slice is not
+// a slice.
+func SortBy(slice any, keys ...string) {
+	SortByWith(slice, keys)
+}
+
+// SortByWith is like SortBy, but with options that modify how keys are
+// compared.
+func SortByWith(slice any, keys []string, opts ...Option) {
+	v := reflect.ValueOf(slice)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if k := v.Kind(); k != reflect.Slice && (k != reflect.Array || !v.CanAddr()) {
+		panic(&UncomparableError{Type: typeOf(v)})
+	}
+
+	sks, err := sortKeysOf(v.Type().Elem(), keys)
+	if err != nil {
+		panic(err)
+	}
+	s := newState(opts)
+	v = v.Slice(0, v.Len())
+	sort.SliceStable(v.Interface(), func(i, j int) bool {
+		return lessBy(s, sks, v.Index(i), v.Index(j))
+	})
+}
+
+// sortKey is a key resolved against an element type: the index of each field
+// in the key's path, with pointers dereferenced between fields.
+type sortKey struct {
+	fields [][]int
+	c      *compiled
+	desc   bool
+}
+
+func sortKeysOf(t reflect.Type, keys []string) ([]sortKey, error) {
+	sks := make([]sortKey, 0, len(keys))
+	for _, key := range keys {
+		sk := sortKey{}
+		path := key
+		switch {
+		case strings.HasPrefix(path, "-"):
+			sk.desc = true
+			path = path[1:]
+		case strings.HasPrefix(path, "+"):
+			path = path[1:]
+		}
+		ft := t
+		for name := range strings.SplitSeq(path, ".") {
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("types: invalid sort key %q: %v is not a struct", key, ft)
+			}
+			sf, ok := ft.FieldByName(name)
+			if !ok || !sf.IsExported() {
+				return nil, fmt.Errorf("types: invalid sort key %q: %v has no exported field %q", key, ft, name)
+			}
+			sk.fields = append(sk.fields, sf.Index)
+			ft = sf.Type
+		}
+		sk.c = compile(ft)
+		sks = append(sks, sk)
+	}
+	return sks, nil
+}
+
+// value returns the key's value in v, or false if the key is missing because
+// v or a pointer along the key's path is nil.
+func (sk *sortKey) value(v reflect.Value) (reflect.Value, bool) {
+	for _, index := range sk.fields {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		var err error
+		if v, err = v.FieldByIndexErr(index); err != nil {
+			return v, false // nil embedded pointer
+		}
+	}
+	return v, true
+}
+
+func lessBy(s *state, sks []sortKey, l, r reflect.Value) bool {
+	for i := range sks {
+		sk := &sks[i]
+		desc := sk.desc != s.o.descending
+		if desc {
+			s.desc = !s.desc
+		}
+		lv, lok := sk.value(l)
+		rv, rok := sk.value(r)
+		var lt, eq bool
+		if lok && rok {
+			lt, eq = sk.c.lteq(s, lv, rv)
+		} else {
+			lt, eq = s.nilLt(!lok), lok == rok
+		}
+		if desc {
+			s.desc = !s.desc
+		}
+		if !eq {
+			return lt != desc
+		}
+	}
+	return false
+}
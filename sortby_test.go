@@ -0,0 +1,78 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSortBy(t *testing.T) {
+	type address struct {
+		City string
+	}
+	type user struct {
+		First    string
+		LastName string
+		Age      int
+		Address  *address
+	}
+
+	a, b := &address{"a"}, &address{"b"}
+	users := []user{
+		{"1", "y", 30, b},
+		{"2", "x", 20, a},
+		{"3", "y", 40, a},
+		{"4", "x", 20, nil},
+		{"5", "x", 20, b},
+		{"6", "x", 20, a},
+	}
+	SortBy(users, "LastName", "-Age", "Address.City")
+	var got []string
+	for _, u := range users {
+		got = append(got, u.First)
+	}
+	if exp := []string{"4", "2", "6", "5", "3", "1"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("got order %v != exp %v", got, exp)
+	}
+
+	// Pointer elements, a pointer to an array, and NilsLast with a
+	// descending key.
+	ptrs := [3]*user{{Age: 1}, nil, {Age: 2}}
+	SortByWith(&ptrs, []string{"-Age"}, NilsLast())
+	if ptrs[0].Age != 2 || ptrs[1].Age != 1 || ptrs[2] != nil {
+		t.Errorf("got %v, exp ages 2, 1, nil", ptrs)
+	}
+}
+
+func TestSortByInvalid(t *testing.T) {
+	type inner struct {
+		N int
+	}
+	type row struct {
+		In     inner
+		hidden int
+	}
+	for _, test := range []struct {
+		slice any
+		key   string
+		err   string
+	}{
+		{[]row{}, "Missing", `no exported field "Missing"`},
+		{[]row{}, "hidden", `no exported field "hidden"`},
+		{[]row{}, "In.N.X", "is not a struct"},
+		{[]int{}, "X", "is not a struct"},
+		{[2]row{}, "In", "cannot compare"},
+		{nil, "In", "cannot compare"},
+	} {
+		func() {
+			defer func() {
+				r := recover()
+				err, _ := r.(error)
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("key %q: got panic %v, exp %q", test.key, r, test.err)
+				}
+			}()
+			SortBy(test.slice, test.key)
+		}()
+	}
+}
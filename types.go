@@ -1,9 +1,27 @@
 // Package types contains helper functions for arbitrary types (deep less,
-// deep equal, deep sort).
+// deep equal, deep diff, deep hash, deep sort).
+//
+// # Struct tags
+//
+// How a struct field is compared and sorted can be controlled with a types
+// struct tag, which is a comma separated list of options:
+//
+//	Ignored  int      `types:"-"`       // never compared nor sorted
+//	Priority int      `types:"order=1"` // compared before other fields
+//	Age      int      `types:"desc"`    // larger values are less
+//	Labels   []string `types:"set"`     // compared as a multiset
+//
+// Fields with an explicit order are compared first, from lowest to highest
+// order, and then all remaining fields are compared in declaration order. A
+// set field is compared as if both sides were sorted first, so the order of
+// elements does not matter but the number of duplicates does; it must be a
+// slice or an array. An invalid tag panics the first time its struct type is
+// used.
 package types
 
 import (
 	"cmp"
+	"math"
 	"reflect"
 	"slices"
 	"sort"
@@ -11,42 +29,72 @@ import (
 )
 
 // Less returns whether l is deeply less than r. The input values must have the
-// same type, or this will panic.
+// same type, or this will panic with a *TypeMismatchError; see TryCompare for
+// a variant that returns errors.
 //
 // Structs are deeply less if each field in order is either less or equal to
 // the comparison field. If every field is equal, this returns false. Only
-// public fields are compared.
+// public fields are compared, and the field order can be changed with struct
+// tags (see the package documentation).
 //
 // Nil pointers are less than non-nil pointers. Recursive types compare less
 // following all other rules, or if they recurse sooner.
 //
 // Slices are less if they are shorter, or if each element in order is less
 // than or equal to the other. If all elements are equal and the sizes are
-// equal, this returns false.
+// equal, this returns false. Nil and empty slices and maps are equal, unless
+// changed with the NilEmpty option. The Lexicographic option compares
+// elements first and only uses length as a tie-breaker.
 //
 // Maps are less if they are shorter. If they are of equal size, all keys are
 // treated as a slice and they are compared following the same logic used for
 // slices. If all keys are equal, then all values are compared following
-// similar slice logic.
+// similar slice logic. The Lexicographic option compares sorted keys first,
+// then length, then values.
 //
-// Floats are less if they are NaN, or using a simple comparison.
+// Floats are less if they are NaN, or using a simple comparison. Floats can be
+// compared approximately with options such as FloatAbsTolerance.
 //
 // Complexes are less if their real is less. If the real is equal, then
 // complexes are less if their imaginary is less.
 //
 // Chans are less if they have fewer buffered elements.
 //
-// Functions, interfaces, and unsafe pointers are never less than each other.
+// Interfaces are compared by their dynamic values. Nil interfaces are less than
+// non-nil interfaces, and values of different dynamic types are ordered by the
+// name of their type.
+//
+// Functions and unsafe pointers are never less than each other.
+//
+// At every level, a type T that has a Compare(T) int or Less(T) bool method is
+// ordered with that method rather than by its contents. A type that only has
+// an Equal(T) bool method uses that method for equality and its contents for
+// ordering. Methods are never called on nil pointers or nil interfaces.
+//
+// Some standard library types do not expose their value through exported
+// fields. These are compared by their value: time.Time, big.Int, big.Float,
+// big.Rat, netip.Addr, netip.AddrPort, netip.Prefix, url.URL (by its String),
+// and regexp.Regexp (by its String).
+//
+// Comparers registered with RegisterComparer or passed with WithComparers
+// take precedence over all of the above for their type.
 func Less(l, r any) bool {
-	lt, _ := lteq(new(pointers), reflect.ValueOf(l), reflect.ValueOf(r))
+	return LessWith(l, r)
+}
+
+// LessWith is like Less, but with options that modify the comparison.
+func LessWith(l, r any, opts ...Option) bool {
+	lt, _ := lteq(newState(opts), reflect.ValueOf(l), reflect.ValueOf(r))
 	return lt
 }
 
 // Equal returns whether l is deeply equal to r. The input values must have the
-// same type, or this will panic.
+// same type, or this will panic with a *TypeMismatchError; see TryEqual for a
+// variant that returns errors.
 //
 // Structs are deeply equal if each field is equal. Unlike reflect, this
-// function compares only public fields.
+// function compares only public fields, and fields tagged with `types:"-"` are
+// skipped.
 //
 // Nil pointers are equal to nil pointers. Recursive types are equal following
 // all other rules, or if they recurse at the same time.
@@ -64,23 +112,33 @@ func Less(l, r any) bool {
 //
 // Chans are equal if they have the same amount of buffered elements.
 //
-// Functions, interfaces, and unsafe pointers equal if their pointers are
-// equal.
+// Interfaces are equal if both are nil, or if their dynamic values have the
+// same type and are deeply equal.
+//
+// Functions and unsafe pointers equal if their pointers are equal.
+//
+// Types with a Compare(T) int, Less(T) bool, or Equal(T) bool method are equal
+// according to that method, as described in Less.
 func Equal(l, r any) bool {
-	_, eq := lteq(new(pointers), reflect.ValueOf(l), reflect.ValueOf(r))
+	return EqualWith(l, r)
+}
+
+// EqualWith is like Equal, but with options that modify the comparison.
+func EqualWith(l, r any, opts ...Option) bool {
+	_, eq := lteq(newState(opts), reflect.ValueOf(l), reflect.ValueOf(r))
 	return eq
 }
 
 // Compare returns whether l is less than, equal to, or larger than r,
-// following the same rules as Less and Equal.
+// following the same rules as Less and Equal. CompareExplain also explains
+// which rule decided the result.
 func Compare(l, r any) int {
-	lt, eq := lteq(new(pointers), reflect.ValueOf(l), reflect.ValueOf(r))
-	if lt {
-		return -1
-	} else if eq {
-		return 0
-	}
-	return 1
+	return CompareWith(l, r)
+}
+
+// CompareWith is like Compare, but with options that modify the comparison.
+func CompareWith(l, r any, opts ...Option) int {
+	return newState(opts).compareValues(reflect.ValueOf(l), reflect.ValueOf(r))
 }
 
 type pointers map[unsafe.Pointer]struct{}
@@ -101,219 +159,419 @@ func (p pointers) remove(ptr unsafe.Pointer) {
 	delete(p, ptr)
 }
 
-func (p *pointers) compareValues(a, b reflect.Value) int {
-	lt, eq := lteq(p, a, b)
-	if lt {
-		return -1
-	}
+// state is threaded through every comparison and sort: it tracks the
+// pointers currently being walked to detect recursion, and holds the options
+// for the current call.
+type state struct {
+	p    pointers
+	o    *options
+	path []string // only tracked if o.paths
+
+	ex    *Explanation // only set if explaining; see CompareExplain
+	quiet int          // if non-zero, comparisons do not explain
+
+	// desc is whether the current comparison is within an odd number
+	// of reversals, which NilsFirst and NilsLast undo.
+	desc bool
+
+	report *Report // only set if reporting; see SortReport
+}
+
+func newState(opts []Option) *state {
+	return &state{o: newOptions(opts)}
+}
+
+func (s *state) compareValues(a, b reflect.Value) int {
+	return compareResult(lteq(s, a, b))
+}
+
+func lteq(s *state, lv, rv reflect.Value) (lt, eq bool) {
+	checkTypes("", lv, rv)
+	return compile(lv.Type()).lteqRoot(s, lv, rv)
+}
+
+// reversed compares lv and rv with c in reverse order.
+func (s *state) reversed(c *compiled, lv, rv reflect.Value) (lt, eq bool) {
+	explaining := s.explaining()
+	s.desc = !s.desc
+	lt, eq = c.lteq(s, lv, rv)
+	s.desc = !s.desc
 	if eq {
-		return 0
+		return false, true
 	}
-	return 1
+	if explaining && s.ex.Rule != "" {
+		s.ex.Rule += " (desc)"
+	}
+	return !lt, false
 }
 
-func lteq(p *pointers, lv, rv reflect.Value) (lt, eq bool) {
-	t := lv.Type()
-	if t != rv.Type() {
-		panic("unequal types")
+// nilLt returns whether l is less than r, if exactly one of them is nil, NaN,
+// or empty, and lnil is whether that is l. Reversals above this comparison
+// would also reverse where nils are placed, so with NilsFirst or NilsLast,
+// this undoes them.
+func (s *state) nilLt(lnil bool) bool {
+	switch s.o.nils {
+	case nilsFirst:
+		return lnil != s.desc
+	case nilsLast:
+		return !lnil != s.desc
 	}
+	return lnil
+}
 
-	if k := t.Kind(); k != reflect.Struct {
-		return lteqKind(p, k, lv, rv)
+// lteqSet compares two slices or arrays ignoring the order of their elements:
+// both sides are compared as if sorted, following the normal slice rules.
+// Elements of a set have no meaningful index, so their path is [*].
+func lteqSet(s *state, t reflect.Type, elem *compiled, lv, rv reflect.Value) (lt, eq bool) {
+	if s.o.ignored(t) {
+		return false, true
+	}
+	ll, lr := lv.Len(), rv.Len()
+	if ll == 0 && lr == 0 {
+		return lteqEmpty(s, "slice", lv, rv)
 	}
+	if ll != lr && !s.o.lexicographic {
+		return lteqLen(s, "set", ll, lr)
+	}
+	eq = true
+	if s.o.paths {
+		s.push(anySeg)
+		if !s.o.ignoredPath(s.path) {
+			lt, eq = lteqSorted(s, elem, lv, rv)
+		}
+		s.pop()
+	} else {
+		lt, eq = lteqSorted(s, elem, lv, rv)
+	}
+	if !eq {
+		return lt, false
+	}
+	return lteqLen(s, "set", ll, lr)
+}
 
-	for i := range t.NumField() {
-		sf := t.Field(i)
-		if !sf.IsExported() {
+// lteqSorted compares the elements of two slices or arrays in sorted order, up
+// to the length of the shorter.
+//
+// Sorted order only pairs equal elements if elements are totally ordered.
+// Otherwise, equal elements may sort to different positions, so if the sorted
+// elements differ, we match the elements pairwise before deciding they do.
+func lteqSorted(s *state, elem *compiled, lv, rv reflect.Value) (lt, eq bool) {
+	explained := s.explaining()
+	lelems, relems := sortedElems(s, elem, lv), sortedElems(s, elem, rv)
+	for i := range min(len(lelems), len(relems)) {
+		lt, eq = s.lteqElem(elem, lelems[i], relems[i])
+		if eq {
 			continue
 		}
-
-		lt, eq := lteqKind(p, sf.Type.Kind(), lv.Field(i), rv.Field(i))
-		if !eq {
-			return lt, false
+		if len(lelems) == len(relems) && !totalElems(s.o, elem.t, lelems, relems) && matchElems(s, elem, lelems, relems) {
+			if explained {
+				*s.ex = Explanation{}
+			}
+			return false, true
 		}
+		return lt, false
 	}
-
 	return false, true
 }
 
-func orderedLtEq[T cmp.Ordered](l, r T) (lt, eq bool) {
-	if l == r {
-		return false, true
+// lteqElem compares two elements of a slice or array at the current path.
+func (s *state) lteqElem(elem *compiled, lv, rv reflect.Value) (lt, eq bool) {
+	if s.o.paths {
+		return s.lteqHere(elem, lv, rv)
 	}
-	return l < r, false
+	return elem.lteq(s, lv, rv)
 }
 
-func floatLtEq(l, r float64) (lt, eq bool) {
-	c := cmp.Compare(l, r)
-	return c < 0, c == 0
-}
-
-func c128lt(l, r complex128) (lt, eq bool) {
-	lt, eq = floatLtEq(real(l), real(r))
-	if eq {
-		lt, eq = floatLtEq(imag(l), imag(r))
+// matchElems returns whether every element in lelems is equal to a distinct
+// element in relems, which has the same length.
+func matchElems(s *state, elem *compiled, lelems, relems []reflect.Value) bool {
+	s.quiet++
+	defer func() { s.quiet-- }()
+	used := make([]bool, len(relems))
+outer:
+	for _, l := range lelems {
+		for j, r := range relems {
+			if used[j] {
+				continue
+			}
+			if _, eq := s.lteqElem(elem, l, r); eq {
+				used[j] = true
+				continue outer
+			}
+		}
+		return false
 	}
-	return lt, eq
+	return true
 }
 
-func lteqKind(p *pointers, k reflect.Kind, lv, rv reflect.Value) (lt, eq bool) {
-	switch k {
-	case reflect.Bool:
-		l, r := lv.Bool(), rv.Bool()
-		return !l && r, l == r
-	case reflect.Int,
-		reflect.Int8,
-		reflect.Int16,
-		reflect.Int32,
-		reflect.Int64:
-		return orderedLtEq(lv.Int(), rv.Int())
-	case reflect.Uint,
-		reflect.Uint8,
-		reflect.Uint16,
-		reflect.Uint32,
-		reflect.Uint64,
-		reflect.Uintptr:
-		return orderedLtEq(lv.Uint(), rv.Uint())
-	case reflect.Float32,
-		reflect.Float64:
-		return floatLtEq(lv.Float(), rv.Float())
-	case reflect.Complex64,
-		reflect.Complex128:
-		return c128lt(lv.Complex(), rv.Complex())
-	case reflect.Chan:
-		ll, lr := lv.Len(), rv.Len()
-		return ll < lr, ll == lr
-	case reflect.Func,
-		reflect.Interface,
-		reflect.UnsafePointer:
-		return false, lv.Interface() == rv.Interface()
-	case reflect.String:
-		return orderedLtEq(lv.String(), rv.String())
-	case reflect.Struct:
-		return lteq(p, lv, rv)
-
-	case reflect.Array,
-		reflect.Slice:
-		ll, lr := lv.Len(), rv.Len()
-		lt, eq = ll < lr, ll == lr
-		if eq {
-			for i := range lr {
-				lt, eq = lteq(p, lv.Index(i), rv.Index(i))
-				if !eq {
-					return lt, false
-				}
+// totalElems returns whether the elements of type t are totally ordered: for
+// any two elements, either they are equal or one is less than the other. If t
+// is an interface, the dynamic types of the elements are checked.
+func totalElems(o *options, t reflect.Type, lelems, relems []reflect.Value) bool {
+	if t.Kind() != reflect.Interface || o.comparer(t) != nil || o.ignored(t) || methodsOf(t) != nil {
+		return totalOrder(o, t, nil)
+	}
+	for _, elems := range [][]reflect.Value{lelems, relems} {
+		for _, e := range elems {
+			if !e.IsNil() && !totalOrder(o, e.Elem().Type(), nil) {
+				return false
 			}
 		}
-		return lt, eq
+	}
+	return true
+}
 
+// totalOrder returns whether values of t are totally ordered. Functions,
+// unsafe pointers, and channels are only ever equal or unequal, as are types
+// with only an Equal method, and interfaces may hold any of these. Types in
+// seen are being checked further up, and are assumed total.
+func totalOrder(o *options, t reflect.Type, seen map[reflect.Type]bool) bool {
+	if o.comparer(t) != nil || o.ignored(t) {
+		return true
+	}
+	if m := methodsOf(t); m != nil {
+		return m.orders()
+	}
+	if seen[t] {
+		return true
+	}
+	if seen == nil {
+		seen = make(map[reflect.Type]bool)
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Func, reflect.UnsafePointer, reflect.Chan, reflect.Interface:
+		return false
+	case reflect.Array, reflect.Slice, reflect.Pointer:
+		return totalOrder(o, t.Elem(), seen)
 	case reflect.Map:
-		ll, lr := lv.Len(), rv.Len()
-		lt, eq = ll < lr, ll == lr
-		if eq {
-			lkeys := lv.MapKeys()
-			rkeys := rv.MapKeys()
-			slices.SortFunc(lkeys, p.compareValues)
-			slices.SortFunc(rkeys, p.compareValues)
-
-			for i, lk := range lkeys {
-				rk := rkeys[i]
-				lt, eq = lteq(p, lk, rk)
-				if !eq {
-					return lt, false
-				}
-			}
-			for _, lk := range lkeys {
-				lval := lv.MapIndex(lk)
-				rval := rv.MapIndex(lk)
-				lt, eq = lteq(p, lval, rval)
-				if !eq {
-					return lt, false
-				}
+		return totalOrder(o, t.Key(), seen) && totalOrder(o, t.Elem(), seen)
+	case reflect.Struct:
+		for _, f := range fieldsOf(t) {
+			if !totalOrder(o, f.typ, seen) {
+				return false
 			}
 		}
-		return lt, eq
+	}
+	return true
+}
 
-	case reflect.Pointer:
-		if lv.IsNil() {
-			return !rv.IsNil(), rv.IsNil()
-		} else if rv.IsNil() {
-			return false, false
-		}
+// lteqLen compares the lengths of two slices or maps (what).
+func lteqLen(s *state, what string, ll, lr int) (lt, eq bool) {
+	lt, eq = ll < lr, ll == lr
+	if !eq && (ll == 0 || lr == 0) {
+		lt = s.nilLt(ll == 0)
+	}
+	if !eq && s.explaining() {
+		s.explain("%s length %d %s %d", what, ll, op(lt), lr)
+	}
+	return lt, eq
+}
 
-		lptr, rptr := unsafe.Pointer(lv.Pointer()), unsafe.Pointer(rv.Pointer())
-		if lptr == rptr {
-			return false, true
-		}
-		lhas, rhas := p.hasOrAdd(lptr), p.hasOrAdd(rptr)
-		if !lhas {
-			defer p.remove(lptr)
-		}
-		if !rhas {
-			defer p.remove(rptr)
-		}
+// lteqEmpty compares two empty slices or maps (what).
+func lteqEmpty(s *state, what string, lv, rv reflect.Value) (lt, eq bool) {
+	lt, eq = s.o.lteqEmpty(lv, rv)
+	if !eq {
+		lt = s.nilLt(lv.IsNil())
+	}
+	if !eq && s.explaining() {
+		s.explainNil(lt, what)
+	}
+	return lt, eq
+}
 
-		if lhas {
-			return !rhas, rhas
-		} else if rhas {
-			return false, false
-		}
+type mapEntry struct {
+	k, v reflect.Value
+}
 
-		lv, rv = reflect.Indirect(lv), reflect.Indirect(rv)
-		k = lv.Type().Kind()
+// sortedEntries returns the entries of the map v sorted by key. Values are
+// paired with their keys while iterating, rather than looked up afterwards,
+// so that keys that cannot be looked up (such as NaN) are still compared.
+func sortedEntries(s *state, key *compiled, v reflect.Value) []mapEntry {
+	ents := make([]mapEntry, 0, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		ents = append(ents, mapEntry{iter.Key(), iter.Value()})
+	}
+	s.quiet++
+	slices.SortFunc(ents, func(l, r mapEntry) int { return key.compare(s, l.k, r.k) })
+	s.quiet--
+	return ents
+}
 
-		return lteqKind(p, k, lv, rv)
+func sortedElems(s *state, elem *compiled, v reflect.Value) []reflect.Value {
+	elems := make([]reflect.Value, v.Len())
+	for i := range elems {
+		elems[i] = v.Index(i)
+	}
+	s.quiet++
+	slices.SortFunc(elems, func(l, r reflect.Value) int { return elem.compare(s, l, r) })
+	s.quiet--
+	return elems
+}
 
-	default:
-		return false, false // reflect.Invalid
+// lteqTypes orders types by their name, and then by their package path. Types
+// that are still indistinguishable (such as types with the same name declared
+// in different functions) are ordered by their address, which is stable for
+// the life of the program but not across programs.
+func lteqTypes(l, r reflect.Type) (lt, eq bool) {
+	if l == r {
+		return false, true
+	}
+	if lt, eq = orderedLtEq(l.String(), r.String()); !eq {
+		return lt, false
+	}
+	if lt, eq = orderedLtEq(l.PkgPath(), r.PkgPath()); !eq {
+		return lt, false
 	}
+	lt, _ = orderedLtEq(reflect.ValueOf(l).Pointer(), reflect.ValueOf(r).Pointer())
+	return lt, false
+}
+
+func orderedLtEq[T cmp.Ordered](l, r T) (lt, eq bool) {
+	if l == r {
+		return false, true
+	}
+	return l < r, false
+}
+
+// floatLtEq compares two floats that are bits wide, using the tolerances in
+// the options for equality and placing NaNs with nils.
+func floatLtEq(s *state, l, r float64, bits int) (lt, eq bool) {
+	c := cmp.Compare(l, r)
+	if c != 0 && s.o.tolerant && s.o.withinTolerance(l, r, bits) {
+		return false, true
+	}
+	if c != 0 && s.o.nils != nilsNatural {
+		if ln := math.IsNaN(l); ln != math.IsNaN(r) {
+			return s.nilLt(ln), false
+		}
+	}
+	return c < 0, c == 0
+}
+
+func c128lt(s *state, l, r complex128, bits int) (lt, eq bool) {
+	lt, eq = floatLtEq(s, real(l), real(r), bits/2)
+	if eq {
+		lt, eq = floatLtEq(s, imag(l), imag(r), bits/2)
+	}
+	return lt, eq
 }
 
 // Sort deeply sorts any slice anywhere within s, traversing into maps, slices,
-// and exported struct fields. Any non-primitive type is less than the other
-// following the rules of Less.
+// interfaces, and exported struct fields. Any non-primitive type is less than
+// the other following the rules of Less. Arrays in map values and in
+// interfaces are not addressable, so these values are replaced with a sorted
+// copy; map values at keys that are not equal to themselves, such as NaN,
+// cannot be replaced. Values that cannot be modified are skipped; SortReport
+// lists them.
 //
 // Note that this function performs value copies. This must not be used to sort
 // types that are not safe to copy. For example, this must not sort
 // []struct{sync.Mutex}, but it can sort []*struct{sync.Mutex}.
 //
-// If a slice contains a type that has a Less method that accepts itself and
-// returns a bool, Sort uses that type's Less method to sort the slice.
+// If a slice contains a type that has a Compare method that accepts itself and
+// returns an int, or a Less method that accepts itself and returns a bool,
+// Sort uses that method to sort the slice and does not sort within the
+// elements.
+//
+// Slices are sorted ascending with nils, NaNs, and empty values first. The
+// Descending, NilsFirst, and NilsLast options change this order.
+//
+// Sorting an untyped nil panics with an *UncomparableError; see TrySort for a
+// variant that returns errors.
 func Sort(s any) {
-	innerSort(new(pointers), reflect.ValueOf(s))
+	SortWith(s)
 }
 
-func innerSort(p *pointers, v reflect.Value) (sortable bool) {
+// SortStable is like Sort, but sorts every slice stably; see Stable.
+func SortStable(s any) {
+	SortWith(s, Stable())
+}
+
+// SortWith is like Sort, but with options that modify how values are
+// compared and which values are sorted.
+func SortWith(s any, opts ...Option) {
+	v := reflect.ValueOf(s)
+	if !v.IsValid() {
+		panic(&UncomparableError{})
+	}
+	innerSort(newState(opts), v)
+}
+
+func innerSort(s *state, v reflect.Value) (sortable bool) {
 	t := v.Type()
+	if s.o.ignored(t) || s.o.comparer(t) != nil {
+		return true
+	}
 	switch t.Kind() {
 	case reflect.Pointer:
 		if v.IsNil() {
 			return true
 		}
 		ptr := unsafe.Pointer(v.Pointer())
-		has := p.hasOrAdd(ptr)
+		has := s.p.hasOrAdd(ptr)
 		if has {
 			return true
 		}
-		defer p.remove(ptr)
-		return innerSort(p, reflect.Indirect(v))
+		defer s.p.remove(ptr)
+		return innerSort(s, reflect.Indirect(v))
 	case reflect.Array:
 		if v.Len() == 0 {
 			return true
 		}
 		i0 := v.Index(0)
 		if !i0.CanAddr() {
+			s.skip("array is not addressable")
 			return false
 		}
 		fallthrough
 
 	case reflect.Slice:
+		if t.Kind() == reflect.Slice && s.o.normalize(v) {
+			v.SetZero()
+			return true
+		}
 		v = v.Slice(0, v.Len())
 		if v.Len() == 0 {
 			return true
 		}
+		if s.o.paths {
+			// If every element is ignored, every element compares
+			// equal and there is nothing to sort.
+			s.push(anySeg)
+			ignored := s.o.ignoredPath(s.path)
+			s.pop()
+			if ignored {
+				return true
+			}
+		}
+
+		// Elements that order themselves are sorted with their own
+		// methods, and we do not sort within them. Well known and
+		// registered types are sorted with their comparer.
+		elem := compile(t.Elem())
+		if m, cmp := methodsOf(t.Elem()), s.o.comparer(t.Elem()); cmp != nil || m.orders() {
+			if k := t.Elem().Kind(); cmp == nil && s.o.natural() && m != nil && m.compare < 0 && k != reflect.Pointer && k != reflect.Interface {
+				vslice := make([]reflect.Value, 1)
+				s.sortIndexes(v, func(i, j int) bool {
+					vslice[0] = v.Index(j)
+					return v.Index(i).Method(m.less).Call(vslice)[0].Bool()
+				})
+				return true
+			}
+			sortSlice(s, elem, v)
+			return true
+		}
 
-		switch t.Elem().Kind() {
+		// Primitives are sorted directly if they sort in their
+		// natural order, and otherwise by comparing, below.
+		kind := t.Elem().Kind()
+		if !s.o.natural() {
+			kind = reflect.Invalid
+		} else if s.reporting() && (kind >= reflect.Bool && kind <= reflect.Float64 || kind == reflect.String) {
+			s.noteOrder(v, func(i, j int) bool { lt, _ := elem.lteq(s, v.Index(i), v.Index(j)); return lt })
+		}
+		switch kind {
 		case reflect.Bool:
 			slice := unsafe.Slice((*bool)(unsafe.Pointer(v.Pointer())), v.Len())
 			slices.SortFunc(slice, func(a, b bool) int {
@@ -348,76 +606,264 @@ func innerSort(p *pointers, v reflect.Value) (sortable bool) {
 		case reflect.Uintptr:
 			slices.Sort(unsafe.Slice((*uintptr)(unsafe.Pointer(v.Pointer())), v.Len()))
 		case reflect.Float32:
-			slices.Sort(unsafe.Slice((*float32)(unsafe.Pointer(v.Pointer())), v.Len()))
+			sortFloats(s, unsafe.Slice((*float32)(unsafe.Pointer(v.Pointer())), v.Len()))
 		case reflect.Float64:
-			slices.Sort(unsafe.Slice((*float64)(unsafe.Pointer(v.Pointer())), v.Len()))
+			sortFloats(s, unsafe.Slice((*float64)(unsafe.Pointer(v.Pointer())), v.Len()))
 		case reflect.String:
 			slices.Sort(unsafe.Slice((*string)(unsafe.Pointer(v.Pointer())), v.Len()))
 
-		case reflect.Struct, reflect.Interface:
-			v0 := v.Index(0)
-			t0 := v0.Type()
-			if meth, ok := t0.MethodByName("Less"); ok {
-				less := v0.Method(meth.Index)
-				tless := less.Type()
-				if tless.NumIn() == 1 && tless.NumOut() == 1 && tless.In(0) == t0 && tless.Out(0) == reflect.TypeFor[bool]() {
-					vslice := make([]reflect.Value, 1)
-					sort.Slice(v.Interface(), func(i, j int) bool {
-						vslice[0] = v.Index(j)
-						return v.Index(i).Method(meth.Index).Call(vslice)[0].Bool()
-					})
-					return true
-				}
-			}
-			fallthrough
-
 		default:
 			// Each element of this **top** level slice is sorted,
 			// but now we have to sort each element's innards. We
 			// do this before sorting the type itself, because
 			// sorting innards may change the outer comparison.
 			for i := range v.Len() {
-				if !innerSort(p, v.Index(i)) {
+				if !elem.sortable || !innerSortAt(s, indexSeg, i, v.Index(i)) {
 					break
 				}
 			}
 
-			sort.Slice(v.Interface(), func(i, j int) bool { lt, _ := lteq(p, v.Index(i), v.Index(j)); return lt })
+			sortSlice(s, elem, v)
 		}
 
 	case reflect.Map:
+		if s.o.normalize(v) {
+			v.SetZero()
+			return true
+		}
+		// Map values are not addressable, so arrays held in values
+		// (or in interface values) cannot be sorted in place. We sort
+		// a copy of such values and write the copy back.
 		iter := v.MapRange()
 		for iter.Next() {
-			if !innerSort(p, iter.Value()) {
+			val := iter.Value()
+			if s.o.normalizeEntry(val) {
+				if findable(s, v, iter.Key()) {
+					v.SetMapIndex(iter.Key(), reflect.Zero(val.Type()))
+				}
+				continue
+			}
+			copyVal := holdsArrays(val)
+			if copyVal && !findable(s, v, iter.Key()) {
+				continue
+			}
+			if copyVal {
+				cp := reflect.New(val.Type()).Elem()
+				cp.Set(val)
+				val = cp
+			}
+			if !innerSortAt(s, keySeg, iter.Key(), val) {
 				return false
 			}
+			if copyVal {
+				v.SetMapIndex(iter.Key(), val)
+			}
 		}
 		return true
 	case reflect.Struct:
-		for i := range t.NumField() {
-			sf := t.Field(i)
-			if !sf.IsExported() {
+		for _, f := range fieldsOf(t) {
+			if !s.o.paths {
+				innerSort(s, v.Field(f.index))
 				continue
 			}
-			innerSort(p, v.Field(i))
+			s.push(f.seg)
+			if !s.o.ignoredField(s.path, f.sf) {
+				innerSort(s, v.Field(f.index))
+			}
+			s.pop()
+		}
+	case reflect.Interface:
+		// Dynamic values differ from value to value, so interfaces
+		// are always sortable even if this value is not.
+		if v.IsNil() {
+			return true
 		}
+		elem := v.Elem()
+		if !holdsArrays(elem) {
+			innerSort(s, elem)
+			return true
+		}
+		// Dynamic values are not addressable. We sort a copy of
+		// values that hold arrays and replace the value with the copy.
+		if !v.CanSet() {
+			s.skip("interface holding an array is not settable")
+			return true
+		}
+		cp := reflect.New(elem.Type()).Elem()
+		cp.Set(elem)
+		innerSort(s, cp)
+		v.Set(cp)
 	default:
 		return false
 	}
 	return true
 }
 
+// findable returns whether the map v finds its entry at key k, so that a
+// sorted or normalized copy of the entry's value can be written back. Keys
+// that are not equal to themselves, such as NaN, are never found, and writing
+// them would add an entry rather than replace one, so such values are skipped.
+func findable(s *state, v, k reflect.Value) bool {
+	if v.MapIndex(k).IsValid() {
+		return true
+	}
+	if s.reporting() {
+		s.push(keySeg(k))
+		if !s.o.ignoredPath(s.path) {
+			s.skip("map key cannot be found to write back")
+		}
+		s.pop()
+	}
+	return false
+}
+
+// holdsArrays returns whether v holds non-empty arrays that Sort would sort
+// directly, rather than behind pointers, slices, or maps. Interfaces are
+// looked through, since their dynamic values are copied with v.
+func holdsArrays(v reflect.Value) bool {
+	t := v.Type()
+	if knownComparer(t) != nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Array:
+		return t.Len() > 0
+	case reflect.Struct:
+		for _, f := range fieldsOf(t) {
+			if holdsArrays(v.Field(f.index)) {
+				return true
+			}
+		}
+	case reflect.Interface:
+		return !v.IsNil() && holdsArrays(v.Elem())
+	}
+	return false
+}
+
+// innerSortAt sorts v at the path segment for k, skipping v if the path is
+// ignored. The segment is only formatted if paths are tracked.
+func innerSortAt[K any](s *state, seg func(K) string, k K, v reflect.Value) bool {
+	if !s.o.paths {
+		return innerSort(s, v)
+	}
+	s.push(seg(k))
+	defer s.pop()
+	if s.o.ignoredPath(s.path) {
+		return true
+	}
+	return innerSort(s, v)
+}
+
+// sortSlice sorts the slice v, whose elements are compared with elem.
+func sortSlice(s *state, elem *compiled, v reflect.Value) {
+	if s.o.paths {
+		s.push(anySeg)
+		defer s.pop()
+	}
+	// Elements are at the root of their comparisons, and at [*].
+	if s.o.descending != (s.o.descPaths != nil && s.o.descAt(s.path)) {
+		s.sortIndexes(v, func(i, j int) bool { lt, _ := s.reversed(elem, v.Index(i), v.Index(j)); return lt })
+		return
+	}
+	s.sortIndexes(v, func(i, j int) bool { lt, _ := elem.lteq(s, v.Index(i), v.Index(j)); return lt })
+}
+
+// sortIndexes sorts the slice v with less, stably if requested.
+func (s *state) sortIndexes(v reflect.Value, less func(i, j int) bool) {
+	if s.reporting() {
+		s.noteOrder(v, less)
+	}
+	if s.o.stable {
+		sort.SliceStable(v.Interface(), less)
+	} else {
+		sort.Slice(v.Interface(), less)
+	}
+}
+
+// sortFloats sorts floats, stably if requested. Other primitives that compare
+// equal are identical, but -0 and 0 are equal and distinct, as are NaNs.
+func sortFloats[F float32 | float64](s *state, fs []F) {
+	if s.o.stable {
+		slices.SortStableFunc(fs, cmp.Compare[F])
+	} else {
+		slices.Sort(fs)
+	}
+}
+
+// ElementsMatch returns whether the slices or arrays l and r contain the same
+// elements in any order, following the rules of Equal for each element. Each
+// element must appear the same number of times in both. The input values must
+// have the same type, or this will panic with a *TypeMismatchError.
+//
+// Elements are matched by sorting both sides. Functions, channels, and types
+// with only an Equal method are not ordered, only equal or not, so slices of
+// them (or of values holding them) that differ in sorted order are then
+// matched pairwise, which takes quadratic time.
+//
+// Only the top level is unordered; to also compare nested slices ignoring
+// order, use EqualWith with UnorderedTypes or the set struct tag.
+func ElementsMatch(l, r any) bool {
+	lv, rv := reflect.ValueOf(l), reflect.ValueOf(r)
+	checkTypes("", lv, rv)
+	t := lv.Type()
+	if k := t.Kind(); k != reflect.Slice && k != reflect.Array {
+		panic(&UncomparableError{Type: t})
+	}
+	_, eq := lteqSet(newState(nil), t, compile(t.Elem()), lv, rv)
+	return eq
+}
+
 // DistinctInPlace sorts *s using the rules of Sort in this package, and
 // compacts it in place using the rules of Equal in this package.
 //
 // This is similar to the slice generic version of sorting and compacting, but
 // allows for even more types to be sorted.
 func DistinctInPlace[S ~[]E, E any](s *S) {
+	DistinctInPlaceWith(s)
+}
+
+// DistinctInPlaceWith is like DistinctInPlace, but with options that modify
+// how values are sorted and compared.
+func DistinctInPlaceWith[S ~[]E, E any](s *S, opts ...Option) {
 	v := reflect.ValueOf(s).Elem()
-	p := new(pointers)
-	innerSort(p, v)
+	st := newState(opts)
+	innerSort(st, v)
+	elem := compile(reflect.TypeFor[E]())
 	*s = slices.CompactFunc(*s, func(a, b E) bool {
-		_, eq := lteq(p, reflect.ValueOf(a), reflect.ValueOf(b))
+		_, eq := elem.lteq(st, reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem())
 		return eq
 	})
 }
+
+// LessT is a generic version of Less. Because both values have the type T, a
+// type mismatch is a compile error rather than a panic.
+//
+// Unlike Less, if T is an interface type, l and r may have different dynamic
+// types, which are ordered following the rules for interfaces in Less.
+func LessT[T any](l, r T) bool {
+	lt, _ := lteqT(l, r)
+	return lt
+}
+
+// EqualT is a generic version of Equal, following the same rules as LessT.
+func EqualT[T any](l, r T) bool {
+	_, eq := lteqT(l, r)
+	return eq
+}
+
+// CompareT is a generic version of Compare, following the same rules as
+// LessT.
+func CompareT[T any](l, r T) int {
+	p := &call[T]{s: state{o: &noOptions}, a: l, b: r}
+	return compile(reflect.TypeFor[T]()).compare(&p.s, reflect.ValueOf(&p.a).Elem(), reflect.ValueOf(&p.b).Elem())
+}
+
+func lteqT[T any](l, r T) (lt, eq bool) {
+	p := &call[T]{s: state{o: &noOptions}, a: l, b: r}
+	return compile(reflect.TypeFor[T]()).lteq(&p.s, reflect.ValueOf(&p.a).Elem(), reflect.ValueOf(&p.b).Elem())
+}
+
+// SortT is a generic version of Sort for slices.
+func SortT[S ~[]E, E any](s S) {
+	innerSort(newState(nil), reflect.ValueOf(&s).Elem())
+}
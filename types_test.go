@@ -400,6 +400,53 @@ func TestSort(t *testing.T) {
 			},
 		},
 
+		// Arrays held by value in maps are sorted and written back.
+		{
+			map[string][4]int{"a": {4, 1, 3, 2}},
+			map[string][4]int{"a": {1, 2, 3, 4}},
+		},
+		{
+			map[string]struct{ A [3]string }{"a": {[3]string{"c", "a", "b"}}},
+			map[string]struct{ A [3]string }{"a": {[3]string{"a", "b", "c"}}},
+		},
+		{
+			map[string]struct{ X any }{"a": {[3]int{3, 1, 2}}, "b": {[]int{2, 1}}},
+			map[string]struct{ X any }{"a": {[3]int{1, 2, 3}}, "b": {[]int{1, 2}}},
+		},
+		{
+			[]any{struct{ X any }{[2]int{2, 1}}},
+			[]any{struct{ X any }{[2]int{1, 2}}},
+		},
+		{
+			map[int]map[int][2][2]int{1: {2: {{4, 3}, {2, 1}}}},
+			map[int]map[int][2][2]int{1: {2: {{1, 2}, {3, 4}}}},
+		},
+
+		// Interfaces are sorted through, with arrays replaced by a
+		// sorted copy.
+		{
+			map[string]any{
+				"a": "x",
+				"b": []any{"c", map[string]any{"d": []any{2.0, 1.0}}, "a"},
+				"c": [2]int{2, 1},
+			},
+			map[string]any{
+				"a": "x",
+				"b": []any{map[string]any{"d": []any{1.0, 2.0}}, "a", "c"},
+				"c": [2]int{1, 2},
+			},
+		},
+		{
+			&struct {
+				A any
+				B []any
+			}{[3]string{"c", "b", "a"}, []any{1, []int{2, 1}, nil}},
+			&struct {
+				A any
+				B []any
+			}{[3]string{"a", "b", "c"}, []any{nil, []int{1, 2}, 1}},
+		},
+
 		{
 			[]tless{{3}, {2}, {1}},
 			[]tless{{1}, {2}, {3}},
@@ -416,6 +463,20 @@ func TestSort(t *testing.T) {
 			t.Errorf("got %v != exp %v", test.in, test.exp)
 		}
 	}
+
+	// A NaN key is never found, so its value cannot be written back, and
+	// sorting must not add entries. Maps with NaN keys are never
+	// reflect.DeepEqual, so we check the entries directly.
+	m := map[float64][2]int{math.NaN(): {2, 1}, 1: {2, 1}}
+	Sort(m)
+	if len(m) != 2 || m[1] != [2]int{1, 2} {
+		t.Errorf("got %v, exp two entries with 1 sorted", m)
+	}
+	for k, v := range m {
+		if math.IsNaN(k) && v != [2]int{2, 1} {
+			t.Errorf("got NaN value %v, exp unsorted", v)
+		}
+	}
 }
 
 type tless struct {
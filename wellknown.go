@@ -0,0 +1,153 @@
+package types
+
+import (
+	"hash/maphash"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// comparer compares two values of the same type, returning -1, 0, or 1.
+type comparer func(l, r reflect.Value) int
+
+// hasher writes a value to h such that values its comparer considers equal
+// write the same bytes.
+type hasher func(h *maphash.Hash, v reflect.Value)
+
+type knownType struct {
+	compare comparer
+	hash    hasher
+}
+
+// knownTypes contains comparers for standard library types whose exported
+// fields (or lack thereof) do not describe their value. Registered types are
+// compared as a whole and are never walked into.
+var knownTypes = map[reflect.Type]knownType{
+	reflect.TypeFor[time.Time](): {
+		func(l, r reflect.Value) int {
+			return l.Interface().(time.Time).Compare(r.Interface().(time.Time))
+		},
+		func(h *maphash.Hash, v reflect.Value) {
+			t := v.Interface().(time.Time)
+			writeUint64(h, uint64(t.Unix()))
+			writeUint64(h, uint64(t.Nanosecond()))
+		},
+	},
+	reflect.TypeFor[big.Int](): {
+		func(l, r reflect.Value) int {
+			return ptrTo[big.Int](l).Cmp(ptrTo[big.Int](r))
+		},
+		func(h *maphash.Hash, v reflect.Value) {
+			i := ptrTo[big.Int](v)
+			writeUint64(h, uint64(i.Sign()))
+			h.Write(i.Bytes())
+		},
+	},
+	reflect.TypeFor[big.Float](): {
+		func(l, r reflect.Value) int {
+			return ptrTo[big.Float](l).Cmp(ptrTo[big.Float](r))
+		},
+		func(h *maphash.Hash, v reflect.Value) {
+			// The 'p' format is exact and normalized regardless of
+			// precision, but distinguishes -0 from 0.
+			f := ptrTo[big.Float](v)
+			if f.Sign() == 0 {
+				h.WriteString("0")
+				return
+			}
+			h.WriteString(f.Text('p', 0))
+		},
+	},
+	reflect.TypeFor[big.Rat](): {
+		func(l, r reflect.Value) int {
+			return ptrTo[big.Rat](l).Cmp(ptrTo[big.Rat](r))
+		},
+		func(h *maphash.Hash, v reflect.Value) {
+			h.WriteString(ptrTo[big.Rat](v).RatString())
+		},
+	},
+	reflect.TypeFor[netip.Addr](): {
+		func(l, r reflect.Value) int {
+			return l.Interface().(netip.Addr).Compare(r.Interface().(netip.Addr))
+		},
+		func(h *maphash.Hash, v reflect.Value) {
+			b, _ := v.Interface().(netip.Addr).MarshalBinary()
+			h.Write(b)
+		},
+	},
+	reflect.TypeFor[netip.AddrPort](): {
+		func(l, r reflect.Value) int {
+			return l.Interface().(netip.AddrPort).Compare(r.Interface().(netip.AddrPort))
+		},
+		func(h *maphash.Hash, v reflect.Value) {
+			b, _ := v.Interface().(netip.AddrPort).MarshalBinary()
+			h.Write(b)
+		},
+	},
+	reflect.TypeFor[netip.Prefix](): {
+		func(l, r reflect.Value) int {
+			return l.Interface().(netip.Prefix).Compare(r.Interface().(netip.Prefix))
+		},
+		func(h *maphash.Hash, v reflect.Value) {
+			b, _ := v.Interface().(netip.Prefix).MarshalBinary()
+			h.Write(b)
+		},
+	},
+	reflect.TypeFor[url.URL](): {
+		func(l, r reflect.Value) int {
+			return strings.Compare(ptrTo[url.URL](l).String(), ptrTo[url.URL](r).String())
+		},
+		func(h *maphash.Hash, v reflect.Value) {
+			h.WriteString(ptrTo[url.URL](v).String())
+		},
+	},
+	reflect.TypeFor[regexp.Regexp](): {
+		func(l, r reflect.Value) int {
+			return strings.Compare(ptrTo[regexp.Regexp](l).String(), ptrTo[regexp.Regexp](r).String())
+		},
+		func(h *maphash.Hash, v reflect.Value) {
+			h.WriteString(ptrTo[regexp.Regexp](v).String())
+		},
+	},
+}
+
+// knownCloners deep copies the well known types whose values share memory
+// when copied. Other well known types are immutable and are copied by value.
+var knownCloners = map[reflect.Type]func(dst, src reflect.Value){
+	reflect.TypeFor[big.Int](): func(dst, src reflect.Value) {
+		ptrTo[big.Int](dst).Set(ptrTo[big.Int](src))
+	},
+	reflect.TypeFor[big.Float](): func(dst, src reflect.Value) {
+		x := ptrTo[big.Float](src)
+		ptrTo[big.Float](dst).SetMode(x.Mode()).SetPrec(x.Prec()).Set(x)
+	},
+	reflect.TypeFor[big.Rat](): func(dst, src reflect.Value) {
+		ptrTo[big.Rat](dst).Set(ptrTo[big.Rat](src))
+	},
+}
+
+// knownComparer returns the comparer registered for t, or nil.
+func knownComparer(t reflect.Type) comparer {
+	return knownTypes[t].compare
+}
+
+// knownHasher returns the hasher registered for t, or nil.
+func knownHasher(t reflect.Type) hasher {
+	return knownTypes[t].hash
+}
+
+// ptrTo returns a pointer to the T in v. If v is not addressable, this
+// returns a pointer to a shallow copy, which is fine for the read only
+// methods comparers call.
+func ptrTo[T any](v reflect.Value) *T {
+	if v.CanAddr() {
+		return v.Addr().Interface().(*T)
+	}
+	p := new(T)
+	reflect.ValueOf(p).Elem().Set(v)
+	return p
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestWellKnown(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Second)
+	for _, test := range []struct {
+		l     any
+		r     any
+		less  bool
+		equal bool
+	}{
+		{t0, t1, true, false},
+		{t1, t0, false, false},
+		{t0, t0.In(time.FixedZone("x", 3600)), false, true},
+		{struct{ At time.Time }{t0}, struct{ At time.Time }{t1}, true, false},
+		{&t0, &t1, true, false},
+
+		{*big.NewInt(1), *big.NewInt(2), true, false},
+		{big.NewInt(-5), big.NewInt(-5), false, true},
+		{*big.NewFloat(2.5), *big.NewFloat(1), false, false},
+		{*big.NewRat(1, 3), *big.NewRat(2, 6), false, true},
+
+		{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2"), true, false},
+		{netip.MustParseAddrPort("10.0.0.1:80"), netip.MustParseAddrPort("10.0.0.1:80"), false, true},
+		{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("10.0.0.0/16"), true, false},
+
+		{*mustURL("https://a.com/x"), *mustURL("https://b.com/x"), true, false},
+		{mustURL("https://a.com/x"), mustURL("https://a.com/x"), false, true},
+
+		{*regexp.MustCompile("a+"), *regexp.MustCompile("b+"), true, false},
+		{regexp.MustCompile("a+"), regexp.MustCompile("a+"), false, true},
+
+		//
+	} {
+		lt, eq := Less(test.l, test.r), Equal(test.l, test.r)
+		if lt != test.less {
+			t.Errorf("l %v r %v, got less? %v, exp less? %v", test.l, test.r, lt, test.less)
+		}
+		if eq != test.equal {
+			t.Errorf("l %v r %v, got equal? %v, exp equal? %v", test.l, test.r, eq, test.equal)
+		}
+	}
+}
+
+func TestWellKnownSort(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	type event struct {
+		At   time.Time
+		Name string
+	}
+
+	times := []time.Time{t0.Add(2), t0, t0.Add(1)}
+	Sort(times)
+	if exp := []time.Time{t0, t0.Add(1), t0.Add(2)}; !reflect.DeepEqual(times, exp) {
+		t.Errorf("got %v != exp %v", times, exp)
+	}
+
+	events := []event{{t0.Add(2), "a"}, {t0, "b"}, {t0.Add(1), "c"}}
+	Sort(events)
+	if exp := []event{{t0, "b"}, {t0.Add(1), "c"}, {t0.Add(2), "a"}}; !reflect.DeepEqual(events, exp) {
+		t.Errorf("got %v != exp %v", events, exp)
+	}
+
+	ints := []*big.Int{big.NewInt(3), big.NewInt(-1), big.NewInt(2)}
+	DistinctInPlace(&ints)
+	if exp := []*big.Int{big.NewInt(-1), big.NewInt(2), big.NewInt(3)}; !Equal(ints, exp) {
+		t.Errorf("got %v != exp %v", ints, exp)
+	}
+}
+
+func mustURL(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}